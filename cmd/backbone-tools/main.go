@@ -17,6 +17,10 @@ import (
 
 	"github.com/contiamo/go-base/pkg/tracing"
 	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/errmap"
+	"github.com/trusch/backbone-tools/pkg/migrations"
+	"github.com/trusch/backbone-tools/pkg/services/backbone"
 	"github.com/trusch/backbone-tools/pkg/services/cronjobs"
 	"github.com/trusch/backbone-tools/pkg/services/events"
 	"github.com/trusch/backbone-tools/pkg/services/jobs"
@@ -26,12 +30,15 @@ import (
 var (
 	dbStr      = pflag.String("db", "postgres://postgres@localhost:5432?sslmode=disable", "postgres connect string")
 	listenAddr = pflag.String("listen", ":3001", "listening address")
-	components = pflag.StringSlice("components", []string{"jobs", "cronjobs", "locks", "events"}, "list of components to start up")
+	components = pflag.StringSlice("components", []string{"jobs", "cronjobs", "locks", "events", "backbone"}, "list of components to start up")
 	key        = pflag.String("key", "", "x509 key file")
 	cert       = pflag.String("cert", "", "x509 cert file")
 	ca         = pflag.String("ca", "", "x509 ca cert file")
 	metrics    = pflag.String("metrics", ":8080", "metrics endpoint")
 	logLevel   = pflag.String("log-level", "INFO", "log level")
+	migrate    = pflag.Bool("migrate", true, "apply pending schema migrations on startup")
+	schema     = pflag.String("schema", "", "postgres schema for all tables (default uses the search_path of the connection)")
+	authConfig = pflag.String("auth-config", "", "auth config file with tokens, certificate subjects and policies (disables auth if empty)")
 )
 
 func main() {
@@ -86,6 +93,22 @@ func main() {
 		logrus.Fatal(err)
 	}
 
+	if args := pflag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			logrus.Fatalf("unknown command %q", args[0])
+		}
+		if err := runMigrate(ctx, db, *schema, args[1:]); err != nil {
+			logrus.Fatal(err)
+		}
+		return
+	}
+
+	if *migrate {
+		if err := migrations.Up(ctx, db, *schema); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
 	// setup grpc server with options
 	opts := []grpcserver.Option{
 		grpcserver.WithCredentials(*cert, *key, *ca),
@@ -94,41 +117,66 @@ func main() {
 		grpcserver.WithRecovery(),
 		grpcserver.WithReflection(),
 		grpcserver.WithTracing("", "backbone-tools"),
+		grpcserver.WithErrorScrubbing(errmap.ToStatus),
+	}
+	if *authConfig != "" {
+		cfg, err := auth.LoadConfig(*authConfig)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		opts = append(opts, auth.WithAuth(cfg))
 	}
 
 	grpcServer, err = grpcserver.New(&grpcserver.Config{
 		Options: opts,
 		Extras: []grpc.ServerOption{
-			grpc.MaxSendMsgSize(1 << 12),
+			// clients accept messages of up to 4MB by default, e.g. exported events with large payloads
+			grpc.MaxSendMsgSize(4 << 20),
 		},
 		Register: func(srv *grpc.Server) {
 			var jobsServer api.JobsServer
 			for _, componentName := range *components {
 				switch componentName {
 				case "jobs":
-					jobsServer, err = jobs.NewServer(ctx, db, *dbStr)
+					jobsServer, err = jobs.NewServer(ctx, db, *dbStr, *schema)
 					if err != nil {
 						logrus.Fatal(err)
 					}
 					api.RegisterJobsServer(srv, jobsServer)
 				case "cronjobs":
-					cronjobsServer, err := cronjobs.NewServer(ctx, db, jobsServer)
+					cronjobsServer, err := cronjobs.NewServer(ctx, db, jobsServer, *schema)
 					if err != nil {
 						logrus.Fatal(err)
 					}
 					api.RegisterCronJobsServer(srv, cronjobsServer)
 				case "locks":
-					locksServer, err := locks.NewServer(ctx, db, *dbStr)
+					locksServer, err := locks.NewServer(ctx, db, *dbStr, *schema)
 					if err != nil {
 						logrus.Fatal(err)
 					}
 					api.RegisterLocksServer(srv, locksServer)
+					semaphoresServer, err := locks.NewSemaphoresServer(ctx, db, *dbStr, *schema)
+					if err != nil {
+						logrus.Fatal(err)
+					}
+					api.RegisterSemaphoresServer(srv, semaphoresServer)
 				case "events":
-					eventsServer, err := events.NewServer(ctx, db, *dbStr)
+					eventsServer, err := events.NewServer(ctx, db, *dbStr, *schema)
 					if err != nil {
 						logrus.Fatal(err)
 					}
 					api.RegisterEventsServer(srv, eventsServer)
+					schemasServer, err := events.NewSchemasServer(ctx, db, *schema)
+					if err != nil {
+						logrus.Fatal(err)
+					}
+					api.RegisterSchemasServer(srv, schemasServer)
+				case "backbone":
+					backboneServer, err := backbone.NewServer(ctx, db, *schema)
+					if err != nil {
+						logrus.Fatal(err)
+					}
+					api.RegisterBackboneServer(srv, backboneServer)
 				}
 			}
 		},
@@ -0,0 +1,79 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// acquireSemaphoreCmd represents the semaphores acquire command
+var acquireSemaphoreCmd = &cobra.Command{
+	Use:   "acquire",
+	Short: "acquire semaphore permits",
+	Long:  `acquire semaphore permits, blocks until enough permits are available.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := api.NewSemaphoresClient(grpcConnection)
+		name, _ := cmd.Flags().GetString("name")
+		permits, _ := cmd.Flags().GetUint32("permits")
+		max, _ := cmd.Flags().GetUint32("max")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		req := &api.SemaphoreAcquireRequest{
+			Name:    name,
+			Permits: permits,
+			Max:     max,
+		}
+		if ttl > 0 {
+			req.Ttl = ptypes.DurationProto(ttl)
+		}
+		if timeout > 0 {
+			req.WaitTimeout = ptypes.DurationProto(timeout)
+		}
+		resp, err := cli.Acquire(context.Background(), req)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		marshaler := jsonpb.Marshaler{
+			Indent: "  ",
+		}
+		err = marshaler.Marshal(os.Stdout, resp)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	semaphoresCmd.AddCommand(acquireSemaphoreCmd)
+	acquireSemaphoreCmd.Flags().String("name", "", "semaphore name")
+	acquireSemaphoreCmd.Flags().Uint32("permits", 1, "number of permits to acquire")
+	acquireSemaphoreCmd.Flags().Uint32("max", 1, "total number of permits of the semaphore")
+	acquireSemaphoreCmd.Flags().Duration("ttl", 0, "time the permits are kept without holding them (server default if unset)")
+	acquireSemaphoreCmd.Flags().Duration("timeout", 0, "give up if the permits can not be taken within this duration (0 waits forever)")
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/trusch/backbone-tools/pkg/api"
 	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -40,9 +41,24 @@ var aquireCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cli := api.NewLocksClient(grpcConnection)
 		id, _ := cmd.Flags().GetString("id")
-		lock, err := cli.Aquire(context.Background(), &api.AquireRequest{
-			Id: id,
-		})
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		shared, _ := cmd.Flags().GetBool("shared")
+		holder, _ := cmd.Flags().GetString("holder")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		req := &api.AquireRequest{
+			Id:     id,
+			Holder: holder,
+		}
+		if shared {
+			req.Mode = api.LockMode_SHARED
+		}
+		if ttl > 0 {
+			req.Ttl = ptypes.DurationProto(ttl)
+		}
+		if timeout > 0 {
+			req.WaitTimeout = ptypes.DurationProto(timeout)
+		}
+		lock, err := cli.Aquire(context.Background(), req)
 		if err != nil {
 			logrus.Fatal(err)
 		}
@@ -59,4 +75,8 @@ var aquireCmd = &cobra.Command{
 func init() {
 	locksCmd.AddCommand(aquireCmd)
 	aquireCmd.Flags().String("id", "", "lock id to aquire")
+	aquireCmd.Flags().String("holder", "", "name of the holder shown when listing locks")
+	aquireCmd.Flags().Bool("shared", false, "aquire the lock in shared mode")
+	aquireCmd.Flags().Duration("ttl", 0, "time the lock is kept without holding it (server default if unset)")
+	aquireCmd.Flags().Duration("timeout", 0, "give up if the lock can not be taken within this duration (0 waits forever)")
 }
@@ -0,0 +1,102 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// consumeCmd represents the consume command
+var consumeCmd = &cobra.Command{
+	Use:   "consume",
+	Short: "consume events of a consumer group",
+	Long: `consume events of a consumer group.
+Every event is acknowledged after it has been printed, events which are not acknowledged
+within the visibility timeout are delivered again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := api.NewEventsClient(grpcConnection)
+		topic, _ := cmd.Flags().GetString("topic")
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		group, _ := cmd.Flags().GetString("group")
+		sinceSeq, _ := cmd.Flags().GetUint64("since-sequence")
+		visibility, _ := cmd.Flags().GetDuration("visibility-timeout")
+		maxInFlight, _ := cmd.Flags().GetUint32("max-in-flight")
+		stream, err := cli.Consume(context.Background())
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		err = stream.Send(&api.ConsumeRequest{
+			Subscribe: &api.SubscribeRequest{
+				Topic:         topic,
+				Labels:        parseLabels(labels),
+				SinceSequence: sinceSeq,
+				Group:         group,
+			},
+			VisibilityTimeout: ptypes.DurationProto(visibility),
+			MaxInFlight:       maxInFlight,
+		})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		marshaler := jsonpb.Marshaler{
+			Indent: "  ",
+		}
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				logrus.Fatal(err)
+			}
+			err = marshaler.Marshal(os.Stdout, event)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			fmt.Println("")
+			err = stream.Send(&api.ConsumeRequest{Acks: []uint64{event.GetSequence()}})
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+	},
+}
+
+func init() {
+	eventsCmd.AddCommand(consumeCmd)
+	consumeCmd.Flags().String("topic", "", "topic or pattern to listen on (orders.*.created, orders.>)")
+	consumeCmd.Flags().String("group", "", "consumer group to join, acknowledged events are committed for the group")
+	consumeCmd.Flags().Uint64("since-sequence", 0, "sequence after which a new group starts")
+	consumeCmd.Flags().StringSlice("label", []string{}, "labels used to filter")
+	consumeCmd.Flags().Duration("visibility-timeout", 30*time.Second, "time after which unacknowledged events are delivered again")
+	consumeCmd.Flags().Uint32("max-in-flight", 100, "maximum number of unacknowledged events")
+}
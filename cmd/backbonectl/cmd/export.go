@@ -0,0 +1,123 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the events export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export stored events",
+	Long: `export the stored events of a time window ordered by sequence.
+The jsonl format writes one event per line, the json format an indented array of events.
+Both can be read by events import.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic, _ := cmd.Flags().GetString("topic")
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+		format, _ := cmd.Flags().GetString("format")
+		marshaler := jsonpb.Marshaler{}
+		switch format {
+		case "jsonl":
+		case "json":
+			marshaler.Indent = "  "
+		default:
+			logrus.Fatalf("unknown format %q (supported are jsonl and json)", format)
+		}
+		cli := api.NewEventsClient(grpcConnection)
+		resp, err := cli.Export(context.Background(), &api.ExportRequest{
+			Topic:  topic,
+			Labels: parseLabels(labels),
+			From:   parseTimestamp("since", since),
+			To:     parseTimestamp("until", until),
+		})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		exported := 0
+		for {
+			event, err := resp.Recv()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				logrus.Fatal(err)
+			}
+			switch {
+			case format == "json" && exported == 0:
+				fmt.Println("[")
+			case format == "json":
+				fmt.Println(",")
+			}
+			err = marshaler.Marshal(os.Stdout, event)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if format == "jsonl" {
+				fmt.Println("")
+			}
+			exported++
+		}
+		switch {
+		case format == "json" && exported == 0:
+			fmt.Println("[]")
+		case format == "json":
+			fmt.Println("\n]")
+		}
+	},
+}
+
+// parseTimestamp parses an RFC 3339 time given with the named flag, it returns nil for empty values
+func parseTimestamp(flag, value string) *timestamp.Timestamp {
+	if value == "" {
+		return nil
+	}
+	var ts timestamp.Timestamp
+	err := jsonpb.Unmarshal(strings.NewReader(fmt.Sprintf(`"%s"`, value)), &ts)
+	if err != nil {
+		logrus.Fatal(errors.Wrapf(err, "failed parsing --%s parameter", flag))
+	}
+	return &ts
+}
+
+func init() {
+	eventsCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("topic", "", "topic or pattern to export (orders.*.created, orders.>)")
+	exportCmd.Flags().String("since", "", "only export events created at or after this time (RFC 3339)")
+	exportCmd.Flags().String("until", "", "only export events created before this time (RFC 3339)")
+	exportCmd.Flags().String("format", "jsonl", "output format, jsonl or json")
+	exportCmd.Flags().StringSlice("label", []string{}, "labels used to filter")
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// getSchemaCmd represents the schemas get command
+var getSchemaCmd = &cobra.Command{
+	Use:   "get",
+	Short: "get the schema of a topic",
+	Long:  `get the schema of a topic.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic, _ := cmd.Flags().GetString("topic")
+		version, _ := cmd.Flags().GetUint32("version")
+		cli := api.NewSchemasClient(grpcConnection)
+		resp, err := cli.Get(context.Background(), &api.GetSchemaRequest{
+			Topic:   topic,
+			Version: version,
+		})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		marshaler := jsonpb.Marshaler{
+			Indent: "  ",
+		}
+		err = marshaler.Marshal(os.Stdout, resp)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	schemasCmd.AddCommand(getSchemaCmd)
+	getSchemaCmd.Flags().String("topic", "", "topic of the schema")
+	getSchemaCmd.Flags().Uint32("version", 0, "version of the schema (default latest)")
+}
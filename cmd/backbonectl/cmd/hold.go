@@ -39,8 +39,10 @@ var holdCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cli := api.NewLocksClient(grpcConnection)
 		id, _ := cmd.Flags().GetString("id")
+		token, _ := cmd.Flags().GetString("owner-token")
 		lock, err := cli.Hold(context.Background(), &api.HoldRequest{
-			Id: id,
+			Id:    id,
+			Token: token,
 		})
 		if err != nil {
 			logrus.Fatal(err)
@@ -58,4 +60,5 @@ var holdCmd = &cobra.Command{
 func init() {
 	locksCmd.AddCommand(holdCmd)
 	holdCmd.Flags().String("id", "", "lock id to hold")
+	holdCmd.Flags().String("owner-token", "", "owner token returned by aquire")
 }
@@ -0,0 +1,154 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// importBatchSize is the number of events published in one transaction, the server accepts at most 10000
+const importBatchSize = 10000
+
+// importCmd represents the events import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import events exported with events export",
+	Long: `import events exported with events export in the jsonl or json format from stdin.
+The events are published again with their topic, labels and payload, they get new ids, sequences and creation times.
+Every 10000 events are published in one transaction.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic, _ := cmd.Flags().GetString("topic")
+		cli := api.NewEventsClient(grpcConnection)
+		var (
+			stream api.Events_PublishStreamClient
+			sent   int
+			total  int
+		)
+		flush := func() {
+			if stream == nil {
+				return
+			}
+			if _, err := stream.CloseAndRecv(); err != nil {
+				logrus.Fatal(err)
+			}
+			total += sent
+			stream, sent = nil, 0
+		}
+		err := readEvents(os.Stdin, func(event *api.Event) error {
+			if topic != "" {
+				event.Topic = topic
+			}
+			if stream == nil {
+				var err error
+				stream, err = cli.PublishStream(context.Background())
+				if err != nil {
+					return err
+				}
+			}
+			err := stream.Send(&api.PublishRequest{
+				Topic:   event.GetTopic(),
+				Labels:  event.GetLabels(),
+				Payload: event.GetPayload(),
+			})
+			if err != nil {
+				return err
+			}
+			sent++
+			if sent == importBatchSize {
+				flush()
+			}
+			return nil
+		})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		flush()
+		logrus.Infof("imported %d events", total)
+	},
+}
+
+// readEvents calls fn for every event of a json array or of a stream of json objects, e.g. one per line
+func readEvents(r io.Reader, fn func(*api.Event) error) error {
+	br := bufio.NewReader(r)
+	array, err := startsArray(br)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(br)
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		event := &api.Event{}
+		if err := jsonpb.Unmarshal(bytes.NewReader(raw), event); err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startsArray skips leading whitespace and reports whether the input starts with a json array
+func startsArray(br *bufio.Reader) (bool, error) {
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return c == '[', br.UnreadByte()
+	}
+}
+
+func init() {
+	eventsCmd.AddCommand(importCmd)
+	importCmd.Flags().String("topic", "", "publish all events on this topic instead of their original one")
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// publishBatchCmd represents the publish-batch command
+var publishBatchCmd = &cobra.Command{
+	Use:   "publish-batch",
+	Short: "publish events read from stdin in one transaction",
+	Long: `publish events read from stdin in one transaction.
+Every line is a JSON encoded publish request like {"topic": "orders", "payload": "<base64>"}.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := api.NewEventsClient(grpcConnection)
+		stream, err := cli.PublishStream(context.Background())
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			req := &api.PublishRequest{}
+			if err := jsonpb.Unmarshal(strings.NewReader(line), req); err != nil {
+				logrus.Fatal(err)
+			}
+			if err := stream.Send(req); err != nil {
+				logrus.Fatal(err)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			logrus.Fatal(err)
+		}
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		marshaler := jsonpb.Marshaler{
+			Indent: "  ",
+		}
+		err = marshaler.Marshal(os.Stdout, resp)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	eventsCmd.AddCommand(publishBatchCmd)
+}
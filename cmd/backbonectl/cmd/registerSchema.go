@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// registerSchemaCmd represents the schemas register command
+var registerSchemaCmd = &cobra.Command{
+	Use:   "register",
+	Short: "register a new version of the schema of a topic",
+	Long: `register a new version of the schema of a topic.
+The JSON schema is read from --file or stdin.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic, _ := cmd.Flags().GetString("topic")
+		file, _ := cmd.Flags().GetString("file")
+		compatibility, _ := cmd.Flags().GetString("compatibility")
+		mode, ok := api.SchemaCompatibility_value[strings.ToUpper(compatibility)]
+		if !ok {
+			logrus.Fatalf("unknown compatibility %q", compatibility)
+		}
+		var (
+			doc []byte
+			err error
+		)
+		if file == "" || file == "-" {
+			doc, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			doc, err = ioutil.ReadFile(file)
+		}
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		cli := api.NewSchemasClient(grpcConnection)
+		resp, err := cli.Register(context.Background(), &api.RegisterSchemaRequest{
+			Topic:         topic,
+			Schema:        string(doc),
+			Compatibility: api.SchemaCompatibility(mode),
+		})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		marshaler := jsonpb.Marshaler{
+			Indent: "  ",
+		}
+		err = marshaler.Marshal(os.Stdout, resp)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	schemasCmd.AddCommand(registerSchemaCmd)
+	registerSchemaCmd.Flags().String("topic", "", "topic of the schema")
+	registerSchemaCmd.Flags().String("file", "", "file containing the JSON schema (default stdin)")
+	registerSchemaCmd.Flags().String("compatibility", "backward", "compatibility to the previous version (backward, forward, full or none)")
+}
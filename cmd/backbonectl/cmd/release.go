@@ -39,8 +39,10 @@ var releaseCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cli := api.NewLocksClient(grpcConnection)
 		id, _ := cmd.Flags().GetString("id")
+		token, _ := cmd.Flags().GetString("owner-token")
 		lock, err := cli.Release(context.Background(), &api.ReleaseRequest{
-			Id: id,
+			Id:    id,
+			Token: token,
 		})
 		if err != nil {
 			logrus.Fatal(err)
@@ -58,4 +60,5 @@ var releaseCmd = &cobra.Command{
 func init() {
 	locksCmd.AddCommand(releaseCmd)
 	releaseCmd.Flags().String("id", "", "lock id to release")
+	releaseCmd.Flags().String("owner-token", "", "owner token returned by aquire")
 }
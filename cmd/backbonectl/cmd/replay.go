@@ -0,0 +1,74 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// replayCmd represents the events replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "replay events into another topic",
+	Long: `publish copies of the events of a time window on another topic in one transaction.
+The copies carry the labels @system/replayed-topic and @system/replayed-sequence.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic, _ := cmd.Flags().GetString("topic")
+		target, _ := cmd.Flags().GetString("target-topic")
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+		cli := api.NewEventsClient(grpcConnection)
+		resp, err := cli.Replay(context.Background(), &api.ReplayRequest{
+			Topic:       topic,
+			TargetTopic: target,
+			Labels:      parseLabels(labels),
+			From:        parseTimestamp("since", since),
+			To:          parseTimestamp("until", until),
+		})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		marshaler := jsonpb.Marshaler{
+			Indent: "  ",
+		}
+		err = marshaler.Marshal(os.Stdout, resp)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	eventsCmd.AddCommand(replayCmd)
+	replayCmd.Flags().String("topic", "", "topic or pattern to replay (orders.*.created, orders.>)")
+	replayCmd.Flags().String("target-topic", "", "topic the copies are published on")
+	replayCmd.Flags().String("since", "", "only replay events created at or after this time (RFC 3339)")
+	replayCmd.Flags().String("until", "", "only replay events created before this time (RFC 3339)")
+	replayCmd.Flags().StringSlice("label", []string{}, "labels used to filter")
+}
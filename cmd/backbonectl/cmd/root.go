@@ -31,6 +31,8 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/namespace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
@@ -77,6 +79,8 @@ func init() {
 	rootCmd.PersistentFlags().String("ca", "", "ca certificate to use")
 	rootCmd.PersistentFlags().Bool("disable-tls", false, "disable")
 	rootCmd.PersistentFlags().String("log-level", "INFO", "log level")
+	rootCmd.PersistentFlags().String("namespace", namespace.Default, "namespace to work in")
+	rootCmd.PersistentFlags().String("token", "", "bearer token to authenticate with")
 	viper.SetEnvPrefix("BACKBONECTL")
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.BindPFlags(rootCmd.PersistentFlags())
@@ -121,12 +125,17 @@ func initConnection() {
 		opts = []grpc.DialOption{
 			grpc.WithBlock(),
 			grpc.WithBackoffMaxDelay(5 * time.Second),
+			grpc.WithUnaryInterceptor(namespace.UnaryClientInterceptor(viper.GetString("namespace"))),
+			grpc.WithStreamInterceptor(namespace.StreamClientInterceptor(viper.GetString("namespace"))),
 		}
 		addr   = viper.GetString("server")
 		caFile = viper.GetString("ca")
 		err    error
 	)
 	logrus.Debugf("try connecting server %s", addr)
+	if token := viper.GetString("token"); token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.BearerToken(token, viper.GetBool("disable-tls"))))
+	}
 	if viper.GetBool("disable-tls") {
 		opts = append(opts, grpc.WithInsecure())
 	} else {
@@ -48,6 +48,7 @@ var subscribeCmd = &cobra.Command{
 		labelMap := parseLabels(labels)
 		sinceSeq, _ := cmd.Flags().GetUint64("since-sequence")
 		sinceStr, _ := cmd.Flags().GetString("since")
+		group, _ := cmd.Flags().GetString("group")
 		var sinceProtoTime timestamp.Timestamp
 		if sinceStr != "" {
 			err := jsonpb.Unmarshal(strings.NewReader(fmt.Sprintf(`"%s"`, sinceStr)), &sinceProtoTime)
@@ -60,6 +61,7 @@ var subscribeCmd = &cobra.Command{
 			Labels:         labelMap,
 			SinceCreatedAt: &sinceProtoTime,
 			SinceSequence:  sinceSeq,
+			Group:          group,
 		})
 		if err != nil {
 			logrus.Fatal(err)
@@ -86,8 +88,9 @@ var subscribeCmd = &cobra.Command{
 
 func init() {
 	eventsCmd.AddCommand(subscribeCmd)
-	subscribeCmd.Flags().String("topic", "", "topic to listen on")
+	subscribeCmd.Flags().String("topic", "", "topic or pattern to listen on (orders.*.created, orders.>)")
 	subscribeCmd.Flags().String("since", "", "only show events newer than this")
+	subscribeCmd.Flags().String("group", "", "consumer group to join, events are distributed among the group members")
 	subscribeCmd.Flags().Uint64("since-sequence", 0, "only show events newer than this")
 	subscribeCmd.Flags().StringSlice("label", []string{}, "labels used to filter")
 }
@@ -0,0 +1,77 @@
+/*
+Copyright © 2020 Tino Rusch <tino.rusch@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// tryCmd represents the try command
+var tryCmd = &cobra.Command{
+	Use:   "try",
+	Short: "try to aquire a lock without waiting",
+	Long:  `try to aquire a lock without waiting. If the lock is held, the current holder is printed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := api.NewLocksClient(grpcConnection)
+		id, _ := cmd.Flags().GetString("id")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		shared, _ := cmd.Flags().GetBool("shared")
+		holder, _ := cmd.Flags().GetString("holder")
+		req := &api.AquireRequest{
+			Id:     id,
+			Holder: holder,
+		}
+		if shared {
+			req.Mode = api.LockMode_SHARED
+		}
+		if ttl > 0 {
+			req.Ttl = ptypes.DurationProto(ttl)
+		}
+		lock, err := cli.TryAquire(context.Background(), req)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		marshaler := jsonpb.Marshaler{
+			Indent: "  ",
+		}
+		err = marshaler.Marshal(os.Stdout, lock)
+		if err != nil {
+			logrus.Fatal(errors.Wrap(err, "failed to marshal lock"))
+		}
+	},
+}
+
+func init() {
+	locksCmd.AddCommand(tryCmd)
+	tryCmd.Flags().String("id", "", "lock id to aquire")
+	tryCmd.Flags().String("holder", "", "name of the holder shown when listing locks")
+	tryCmd.Flags().Bool("shared", false, "aquire the lock in shared mode")
+	tryCmd.Flags().Duration("ttl", 0, "time the lock is kept without holding it (server default if unset)")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/trusch/backbone-tools/pkg/api"
 	"github.com/trusch/backbone-tools/pkg/locks"
+	"github.com/trusch/backbone-tools/pkg/namespace"
 	"github.com/trusch/backbone-tools/pkg/worker"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
@@ -21,6 +22,7 @@ var (
 	cert  = pflag.String("cert", "", "client cert")
 	key   = pflag.String("key", "", "client secret")
 	queue = pflag.String("queue", "example", "queue to listen on")
+	ns    = pflag.String("namespace", namespace.Default, "namespace to work in")
 
 	conn      *grpc.ClientConn
 	jobsCli   api.JobsClient
@@ -39,10 +41,11 @@ func init() {
 		"cert":  *cert,
 		"key":   *key,
 		"queue": *queue,
+		"ns":    *ns,
 	}).Info("parsed flags")
 
 	logrus.Info("try connecting to backbone-tools server...")
-	conn, err = connect(*addr, *cert, *key)
+	conn, err = connect(*addr, *cert, *key, *ns)
 	logrus.Infof("connected.")
 	jobsCli = api.NewJobsClient(conn)
 	locksCli = api.NewLocksClient(conn)
@@ -65,11 +68,13 @@ func echoWorker(ctx context.Context, spec []byte, state chan<- []byte) error {
 	// take a lock to guarantee that only one worker at a time works on a queue
 	// the lock is automatically released when this function returns, since the context is scoped accordingly
 	logrus.Info("try to aquire a lock...")
-	err := locks.Lock(ctx, locksCli, "echo-lock")
+	lease, err := locks.Lock(ctx, locksCli, "echo-lock")
 	if err != nil {
 		return err
 	}
-	logrus.Info("got the lock.")
+	logrus.Infof("got the lock (fencing token %d).", lease.FencingToken)
+	// stop working as soon as the lock is lost
+	ctx = lease.Context()
 
 	// publish an example event
 	logrus.Info("publish an event...")
@@ -106,7 +111,7 @@ func echoWorker(ctx context.Context, spec []byte, state chan<- []byte) error {
 	return nil
 }
 
-func connect(addr, cert, key string) (*grpc.ClientConn, error) {
+func connect(addr, cert, key, ns string) (*grpc.ClientConn, error) {
 	var (
 		opt = grpc.WithInsecure()
 	)
@@ -116,7 +121,10 @@ func connect(addr, cert, key string) (*grpc.ClientConn, error) {
 	conn, err := grpc.Dial(addr, opt, grpc.WithBlock(), grpc.WithConnectParams(grpc.ConnectParams{
 		Backoff:           backoff.DefaultConfig,
 		MinConnectTimeout: 2 * time.Second,
-	}))
+	}),
+		grpc.WithUnaryInterceptor(namespace.UnaryClientInterceptor(ns)),
+		grpc.WithStreamInterceptor(namespace.StreamClientInterceptor(ns)),
+	)
 	if err != nil {
 		return nil, err
 	}
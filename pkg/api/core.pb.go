@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	duration "github.com/golang/protobuf/ptypes/duration"
 	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -25,6 +26,68 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type LockMode int32
+
+const (
+	// only one holder at a time
+	LockMode_EXCLUSIVE LockMode = 0
+	// any number of holders as long as no exclusive holder exists
+	LockMode_SHARED LockMode = 1
+)
+
+var LockMode_name = map[int32]string{
+	0: "EXCLUSIVE",
+	1: "SHARED",
+}
+
+var LockMode_value = map[string]int32{
+	"EXCLUSIVE": 0,
+	"SHARED":    1,
+}
+
+func (x LockMode) String() string {
+	return proto.EnumName(LockMode_name, int32(x))
+}
+
+func (LockMode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{0}
+}
+
+type SchemaCompatibility int32
+
+const (
+	// payloads valid under the previous version stay valid, consumers using the new version can read old events
+	SchemaCompatibility_BACKWARD SchemaCompatibility = 0
+	// payloads valid under the new version are valid under the previous one, existing consumers can read new events
+	SchemaCompatibility_FORWARD SchemaCompatibility = 1
+	// both backward and forward compatible
+	SchemaCompatibility_FULL SchemaCompatibility = 2
+	// no checks against the previous version
+	SchemaCompatibility_NONE SchemaCompatibility = 3
+)
+
+var SchemaCompatibility_name = map[int32]string{
+	0: "BACKWARD",
+	1: "FORWARD",
+	2: "FULL",
+	3: "NONE",
+}
+
+var SchemaCompatibility_value = map[string]int32{
+	"BACKWARD": 0,
+	"FORWARD":  1,
+	"FULL":     2,
+	"NONE":     3,
+}
+
+func (x SchemaCompatibility) String() string {
+	return proto.EnumName(SchemaCompatibility_name, int32(x))
+}
+
+func (SchemaCompatibility) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{1}
+}
+
 type Job struct {
 	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Queue                string               `protobuf:"bytes,2,opt,name=queue,proto3" json:"queue,omitempty"`
@@ -35,6 +98,7 @@ type Job struct {
 	StartedAt            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
 	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	FinishedAt           *timestamp.Timestamp `protobuf:"bytes,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Namespace            string               `protobuf:"bytes,10,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -128,6 +192,13 @@ func (m *Job) GetFinishedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Job) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
 type CronJob struct {
 	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name                 string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
@@ -137,6 +208,7 @@ type CronJob struct {
 	Labels               map[string]string    `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	NextRunAt            *timestamp.Timestamp `protobuf:"bytes,8,opt,name=next_run_at,json=nextRunAt,proto3" json:"next_run_at,omitempty"`
+	Namespace            string               `protobuf:"bytes,9,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -223,6 +295,13 @@ func (m *CronJob) GetNextRunAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *CronJob) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
 type CreateJobRequest struct {
 	Queue                string            `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
 	Spec                 []byte            `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
@@ -593,7 +672,14 @@ func (m *CreateCronJobRequest) GetCron() string {
 }
 
 type AquireRequest struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// maximum time to wait for the lock, waits until the request is canceled if unset
+	WaitTimeout *duration.Duration `protobuf:"bytes,2,opt,name=wait_timeout,json=waitTimeout,proto3" json:"wait_timeout,omitempty"`
+	// time the lock is held without renewal (1s to 1h), the server default is used if unset
+	Ttl  *duration.Duration `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Mode LockMode           `protobuf:"varint,4,opt,name=mode,proto3,enum=api.LockMode" json:"mode,omitempty"`
+	// name of the acquiring client, shown to admins listing locks
+	Holder               string   `protobuf:"bytes,5,opt,name=holder,proto3" json:"holder,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -631,11 +717,47 @@ func (m *AquireRequest) GetId() string {
 	return ""
 }
 
+func (m *AquireRequest) GetWaitTimeout() *duration.Duration {
+	if m != nil {
+		return m.WaitTimeout
+	}
+	return nil
+}
+
+func (m *AquireRequest) GetTtl() *duration.Duration {
+	if m != nil {
+		return m.Ttl
+	}
+	return nil
+}
+
+func (m *AquireRequest) GetMode() LockMode {
+	if m != nil {
+		return m.Mode
+	}
+	return LockMode_EXCLUSIVE
+}
+
+func (m *AquireRequest) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
 type AquireResponse struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// token identifying the owner, required to hold and release the lock
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// increases with every acquisition of the lock, use it to fence writes of stale owners
+	FencingToken uint64 `protobuf:"varint,3,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	// granted ttl, hold the lock well within this interval
+	Ttl                  *duration.Duration   `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	ExpiresAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Mode                 LockMode             `protobuf:"varint,6,opt,name=mode,proto3,enum=api.LockMode" json:"mode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *AquireResponse) Reset()         { *m = AquireResponse{} }
@@ -670,8 +792,304 @@ func (m *AquireResponse) GetId() string {
 	return ""
 }
 
+func (m *AquireResponse) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *AquireResponse) GetFencingToken() uint64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+func (m *AquireResponse) GetTtl() *duration.Duration {
+	if m != nil {
+		return m.Ttl
+	}
+	return nil
+}
+
+func (m *AquireResponse) GetExpiresAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return nil
+}
+
+func (m *AquireResponse) GetMode() LockMode {
+	if m != nil {
+		return m.Mode
+	}
+	return LockMode_EXCLUSIVE
+}
+
+type TryAquireResponse struct {
+	// true if the lock has been acquired
+	Acquired bool `protobuf:"varint,1,opt,name=acquired,proto3" json:"acquired,omitempty"`
+	// owner and fencing tokens if the lock has been acquired
+	Lock *AquireResponse `protobuf:"bytes,2,opt,name=lock,proto3" json:"lock,omitempty"`
+	// the current holder if the lock is taken
+	Holder               *LockInfo `protobuf:"bytes,3,opt,name=holder,proto3" json:"holder,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *TryAquireResponse) Reset()         { *m = TryAquireResponse{} }
+func (m *TryAquireResponse) String() string { return proto.CompactTextString(m) }
+func (*TryAquireResponse) ProtoMessage()    {}
+func (*TryAquireResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{11}
+}
+
+func (m *TryAquireResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TryAquireResponse.Unmarshal(m, b)
+}
+func (m *TryAquireResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TryAquireResponse.Marshal(b, m, deterministic)
+}
+func (m *TryAquireResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TryAquireResponse.Merge(m, src)
+}
+func (m *TryAquireResponse) XXX_Size() int {
+	return xxx_messageInfo_TryAquireResponse.Size(m)
+}
+func (m *TryAquireResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TryAquireResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TryAquireResponse proto.InternalMessageInfo
+
+func (m *TryAquireResponse) GetAcquired() bool {
+	if m != nil {
+		return m.Acquired
+	}
+	return false
+}
+
+func (m *TryAquireResponse) GetLock() *AquireResponse {
+	if m != nil {
+		return m.Lock
+	}
+	return nil
+}
+
+func (m *TryAquireResponse) GetHolder() *LockInfo {
+	if m != nil {
+		return m.Holder
+	}
+	return nil
+}
+
+type LockInfo struct {
+	Id           string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FencingToken uint64               `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	UpdatedAt    *timestamp.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ExpiresAt    *timestamp.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Ttl          *duration.Duration   `protobuf:"bytes,5,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Mode         LockMode             `protobuf:"varint,6,opt,name=mode,proto3,enum=api.LockMode" json:"mode,omitempty"`
+	// number of current holders, more than one for shared locks
+	Holders uint32 `protobuf:"varint,7,opt,name=holders,proto3" json:"holders,omitempty"`
+	// client provided name of the latest holder
+	Holder string `protobuf:"bytes,8,opt,name=holder,proto3" json:"holder,omitempty"`
+	// peer address of the latest holder
+	HolderAddress string               `protobuf:"bytes,9,opt,name=holder_address,json=holderAddress,proto3" json:"holder_address,omitempty"`
+	AcquiredAt    *timestamp.Timestamp `protobuf:"bytes,10,opt,name=acquired_at,json=acquiredAt,proto3" json:"acquired_at,omitempty"`
+	// number of queued waiters
+	Waiters              uint32   `protobuf:"varint,11,opt,name=waiters,proto3" json:"waiters,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LockInfo) Reset()         { *m = LockInfo{} }
+func (m *LockInfo) String() string { return proto.CompactTextString(m) }
+func (*LockInfo) ProtoMessage()    {}
+func (*LockInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{12}
+}
+
+func (m *LockInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LockInfo.Unmarshal(m, b)
+}
+func (m *LockInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LockInfo.Marshal(b, m, deterministic)
+}
+func (m *LockInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LockInfo.Merge(m, src)
+}
+func (m *LockInfo) XXX_Size() int {
+	return xxx_messageInfo_LockInfo.Size(m)
+}
+func (m *LockInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_LockInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LockInfo proto.InternalMessageInfo
+
+func (m *LockInfo) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *LockInfo) GetFencingToken() uint64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+func (m *LockInfo) GetUpdatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+func (m *LockInfo) GetExpiresAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return nil
+}
+
+func (m *LockInfo) GetTtl() *duration.Duration {
+	if m != nil {
+		return m.Ttl
+	}
+	return nil
+}
+
+func (m *LockInfo) GetMode() LockMode {
+	if m != nil {
+		return m.Mode
+	}
+	return LockMode_EXCLUSIVE
+}
+
+func (m *LockInfo) GetHolders() uint32 {
+	if m != nil {
+		return m.Holders
+	}
+	return 0
+}
+
+func (m *LockInfo) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *LockInfo) GetHolderAddress() string {
+	if m != nil {
+		return m.HolderAddress
+	}
+	return ""
+}
+
+func (m *LockInfo) GetAcquiredAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.AcquiredAt
+	}
+	return nil
+}
+
+func (m *LockInfo) GetWaiters() uint32 {
+	if m != nil {
+		return m.Waiters
+	}
+	return 0
+}
+
+type ListLocksRequest struct {
+	// only list locks with ids starting with this prefix
+	Prefix               string   `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListLocksRequest) Reset()         { *m = ListLocksRequest{} }
+func (m *ListLocksRequest) String() string { return proto.CompactTextString(m) }
+func (*ListLocksRequest) ProtoMessage()    {}
+func (*ListLocksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{13}
+}
+
+func (m *ListLocksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLocksRequest.Unmarshal(m, b)
+}
+func (m *ListLocksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLocksRequest.Marshal(b, m, deterministic)
+}
+func (m *ListLocksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLocksRequest.Merge(m, src)
+}
+func (m *ListLocksRequest) XXX_Size() int {
+	return xxx_messageInfo_ListLocksRequest.Size(m)
+}
+func (m *ListLocksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLocksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLocksRequest proto.InternalMessageInfo
+
+func (m *ListLocksRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+type ForceReleaseRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ForceReleaseRequest) Reset()         { *m = ForceReleaseRequest{} }
+func (m *ForceReleaseRequest) String() string { return proto.CompactTextString(m) }
+func (*ForceReleaseRequest) ProtoMessage()    {}
+func (*ForceReleaseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{14}
+}
+
+func (m *ForceReleaseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ForceReleaseRequest.Unmarshal(m, b)
+}
+func (m *ForceReleaseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ForceReleaseRequest.Marshal(b, m, deterministic)
+}
+func (m *ForceReleaseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ForceReleaseRequest.Merge(m, src)
+}
+func (m *ForceReleaseRequest) XXX_Size() int {
+	return xxx_messageInfo_ForceReleaseRequest.Size(m)
+}
+func (m *ForceReleaseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ForceReleaseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ForceReleaseRequest proto.InternalMessageInfo
+
+func (m *ForceReleaseRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
 type HoldRequest struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Token                string   `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -681,7 +1099,7 @@ func (m *HoldRequest) Reset()         { *m = HoldRequest{} }
 func (m *HoldRequest) String() string { return proto.CompactTextString(m) }
 func (*HoldRequest) ProtoMessage()    {}
 func (*HoldRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f7e43720d1edc0fe, []int{11}
+	return fileDescriptor_f7e43720d1edc0fe, []int{15}
 }
 
 func (m *HoldRequest) XXX_Unmarshal(b []byte) error {
@@ -709,18 +1127,28 @@ func (m *HoldRequest) GetId() string {
 	return ""
 }
 
+func (m *HoldRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
 type HoldResponse struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Id           string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FencingToken uint64 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	// the lock expires at this time unless it is held again
+	ExpiresAt            *timestamp.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *HoldResponse) Reset()         { *m = HoldResponse{} }
 func (m *HoldResponse) String() string { return proto.CompactTextString(m) }
 func (*HoldResponse) ProtoMessage()    {}
 func (*HoldResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f7e43720d1edc0fe, []int{12}
+	return fileDescriptor_f7e43720d1edc0fe, []int{16}
 }
 
 func (m *HoldResponse) XXX_Unmarshal(b []byte) error {
@@ -748,8 +1176,23 @@ func (m *HoldResponse) GetId() string {
 	return ""
 }
 
+func (m *HoldResponse) GetFencingToken() uint64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+func (m *HoldResponse) GetExpiresAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return nil
+}
+
 type ReleaseRequest struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Token                string   `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -759,7 +1202,7 @@ func (m *ReleaseRequest) Reset()         { *m = ReleaseRequest{} }
 func (m *ReleaseRequest) String() string { return proto.CompactTextString(m) }
 func (*ReleaseRequest) ProtoMessage()    {}
 func (*ReleaseRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f7e43720d1edc0fe, []int{13}
+	return fileDescriptor_f7e43720d1edc0fe, []int{17}
 }
 
 func (m *ReleaseRequest) XXX_Unmarshal(b []byte) error {
@@ -787,9 +1230,16 @@ func (m *ReleaseRequest) GetId() string {
 	return ""
 }
 
-type ReleaseResponse struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+func (m *ReleaseRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+type ReleaseResponse struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
@@ -798,7 +1248,7 @@ func (m *ReleaseResponse) Reset()         { *m = ReleaseResponse{} }
 func (m *ReleaseResponse) String() string { return proto.CompactTextString(m) }
 func (*ReleaseResponse) ProtoMessage()    {}
 func (*ReleaseResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f7e43720d1edc0fe, []int{14}
+	return fileDescriptor_f7e43720d1edc0fe, []int{18}
 }
 
 func (m *ReleaseResponse) XXX_Unmarshal(b []byte) error {
@@ -826,23 +1276,354 @@ func (m *ReleaseResponse) GetId() string {
 	return ""
 }
 
-type Event struct {
-	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Topic                string               `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
-	Labels               map[string]string    `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	Sequence             uint64               `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"`
-	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Payload              []byte               `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
+type SemaphoreAcquireRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// number of permits to take, defaults to 1
+	Permits uint32 `protobuf:"varint,2,opt,name=permits,proto3" json:"permits,omitempty"`
+	// total number of permits of the semaphore, it must match the max of the current holders
+	Max uint32 `protobuf:"varint,3,opt,name=max,proto3" json:"max,omitempty"`
+	// time the permits are held without renewal (1s to 1h), the server default is used if unset
+	Ttl *duration.Duration `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// maximum time to wait for the permits, waits until the request is canceled if unset
+	WaitTimeout          *duration.Duration `protobuf:"bytes,5,opt,name=wait_timeout,json=waitTimeout,proto3" json:"wait_timeout,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *SemaphoreAcquireRequest) Reset()         { *m = SemaphoreAcquireRequest{} }
+func (m *SemaphoreAcquireRequest) String() string { return proto.CompactTextString(m) }
+func (*SemaphoreAcquireRequest) ProtoMessage()    {}
+func (*SemaphoreAcquireRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{19}
+}
+
+func (m *SemaphoreAcquireRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SemaphoreAcquireRequest.Unmarshal(m, b)
+}
+func (m *SemaphoreAcquireRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SemaphoreAcquireRequest.Marshal(b, m, deterministic)
+}
+func (m *SemaphoreAcquireRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SemaphoreAcquireRequest.Merge(m, src)
+}
+func (m *SemaphoreAcquireRequest) XXX_Size() int {
+	return xxx_messageInfo_SemaphoreAcquireRequest.Size(m)
+}
+func (m *SemaphoreAcquireRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SemaphoreAcquireRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SemaphoreAcquireRequest proto.InternalMessageInfo
+
+func (m *SemaphoreAcquireRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SemaphoreAcquireRequest) GetPermits() uint32 {
+	if m != nil {
+		return m.Permits
+	}
+	return 0
+}
+
+func (m *SemaphoreAcquireRequest) GetMax() uint32 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+func (m *SemaphoreAcquireRequest) GetTtl() *duration.Duration {
+	if m != nil {
+		return m.Ttl
+	}
+	return nil
+}
+
+func (m *SemaphoreAcquireRequest) GetWaitTimeout() *duration.Duration {
+	if m != nil {
+		return m.WaitTimeout
+	}
+	return nil
+}
+
+type SemaphoreAcquireResponse struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// token identifying the holder, required to hold and release the permits
+	Token                string               `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	Permits              uint32               `protobuf:"varint,3,opt,name=permits,proto3" json:"permits,omitempty"`
+	Ttl                  *duration.Duration   `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	ExpiresAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *SemaphoreAcquireResponse) Reset()         { *m = SemaphoreAcquireResponse{} }
+func (m *SemaphoreAcquireResponse) String() string { return proto.CompactTextString(m) }
+func (*SemaphoreAcquireResponse) ProtoMessage()    {}
+func (*SemaphoreAcquireResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{20}
+}
+
+func (m *SemaphoreAcquireResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SemaphoreAcquireResponse.Unmarshal(m, b)
+}
+func (m *SemaphoreAcquireResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SemaphoreAcquireResponse.Marshal(b, m, deterministic)
+}
+func (m *SemaphoreAcquireResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SemaphoreAcquireResponse.Merge(m, src)
+}
+func (m *SemaphoreAcquireResponse) XXX_Size() int {
+	return xxx_messageInfo_SemaphoreAcquireResponse.Size(m)
+}
+func (m *SemaphoreAcquireResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SemaphoreAcquireResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SemaphoreAcquireResponse proto.InternalMessageInfo
+
+func (m *SemaphoreAcquireResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SemaphoreAcquireResponse) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *SemaphoreAcquireResponse) GetPermits() uint32 {
+	if m != nil {
+		return m.Permits
+	}
+	return 0
+}
+
+func (m *SemaphoreAcquireResponse) GetTtl() *duration.Duration {
+	if m != nil {
+		return m.Ttl
+	}
+	return nil
+}
+
+func (m *SemaphoreAcquireResponse) GetExpiresAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return nil
+}
+
+type SemaphoreHoldRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Token                string   `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SemaphoreHoldRequest) Reset()         { *m = SemaphoreHoldRequest{} }
+func (m *SemaphoreHoldRequest) String() string { return proto.CompactTextString(m) }
+func (*SemaphoreHoldRequest) ProtoMessage()    {}
+func (*SemaphoreHoldRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{21}
+}
+
+func (m *SemaphoreHoldRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SemaphoreHoldRequest.Unmarshal(m, b)
+}
+func (m *SemaphoreHoldRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SemaphoreHoldRequest.Marshal(b, m, deterministic)
+}
+func (m *SemaphoreHoldRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SemaphoreHoldRequest.Merge(m, src)
+}
+func (m *SemaphoreHoldRequest) XXX_Size() int {
+	return xxx_messageInfo_SemaphoreHoldRequest.Size(m)
+}
+func (m *SemaphoreHoldRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SemaphoreHoldRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SemaphoreHoldRequest proto.InternalMessageInfo
+
+func (m *SemaphoreHoldRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SemaphoreHoldRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+type SemaphoreHoldResponse struct {
+	Name                 string               `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ExpiresAt            *timestamp.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
 }
 
+func (m *SemaphoreHoldResponse) Reset()         { *m = SemaphoreHoldResponse{} }
+func (m *SemaphoreHoldResponse) String() string { return proto.CompactTextString(m) }
+func (*SemaphoreHoldResponse) ProtoMessage()    {}
+func (*SemaphoreHoldResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{22}
+}
+
+func (m *SemaphoreHoldResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SemaphoreHoldResponse.Unmarshal(m, b)
+}
+func (m *SemaphoreHoldResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SemaphoreHoldResponse.Marshal(b, m, deterministic)
+}
+func (m *SemaphoreHoldResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SemaphoreHoldResponse.Merge(m, src)
+}
+func (m *SemaphoreHoldResponse) XXX_Size() int {
+	return xxx_messageInfo_SemaphoreHoldResponse.Size(m)
+}
+func (m *SemaphoreHoldResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SemaphoreHoldResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SemaphoreHoldResponse proto.InternalMessageInfo
+
+func (m *SemaphoreHoldResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SemaphoreHoldResponse) GetExpiresAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return nil
+}
+
+type SemaphoreReleaseRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Token                string   `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SemaphoreReleaseRequest) Reset()         { *m = SemaphoreReleaseRequest{} }
+func (m *SemaphoreReleaseRequest) String() string { return proto.CompactTextString(m) }
+func (*SemaphoreReleaseRequest) ProtoMessage()    {}
+func (*SemaphoreReleaseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{23}
+}
+
+func (m *SemaphoreReleaseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SemaphoreReleaseRequest.Unmarshal(m, b)
+}
+func (m *SemaphoreReleaseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SemaphoreReleaseRequest.Marshal(b, m, deterministic)
+}
+func (m *SemaphoreReleaseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SemaphoreReleaseRequest.Merge(m, src)
+}
+func (m *SemaphoreReleaseRequest) XXX_Size() int {
+	return xxx_messageInfo_SemaphoreReleaseRequest.Size(m)
+}
+func (m *SemaphoreReleaseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SemaphoreReleaseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SemaphoreReleaseRequest proto.InternalMessageInfo
+
+func (m *SemaphoreReleaseRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SemaphoreReleaseRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+type SemaphoreReleaseResponse struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SemaphoreReleaseResponse) Reset()         { *m = SemaphoreReleaseResponse{} }
+func (m *SemaphoreReleaseResponse) String() string { return proto.CompactTextString(m) }
+func (*SemaphoreReleaseResponse) ProtoMessage()    {}
+func (*SemaphoreReleaseResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{24}
+}
+
+func (m *SemaphoreReleaseResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SemaphoreReleaseResponse.Unmarshal(m, b)
+}
+func (m *SemaphoreReleaseResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SemaphoreReleaseResponse.Marshal(b, m, deterministic)
+}
+func (m *SemaphoreReleaseResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SemaphoreReleaseResponse.Merge(m, src)
+}
+func (m *SemaphoreReleaseResponse) XXX_Size() int {
+	return xxx_messageInfo_SemaphoreReleaseResponse.Size(m)
+}
+func (m *SemaphoreReleaseResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SemaphoreReleaseResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SemaphoreReleaseResponse proto.InternalMessageInfo
+
+func (m *SemaphoreReleaseResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Event struct {
+	Id     string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Topic  string            `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Labels map[string]string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// sequences grow in commit order within a namespace, resuming after the last seen sequence never skips events
+	Sequence  uint64               `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	CreatedAt *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Payload   []byte               `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
+	Namespace string               `protobuf:"bytes,7,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// position of the event within its topic, topic sequences have no gaps
+	TopicSequence        uint64   `protobuf:"varint,8,opt,name=topic_sequence,json=topicSequence,proto3" json:"topic_sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
 func (m *Event) Reset()         { *m = Event{} }
 func (m *Event) String() string { return proto.CompactTextString(m) }
 func (*Event) ProtoMessage()    {}
 func (*Event) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f7e43720d1edc0fe, []int{15}
+	return fileDescriptor_f7e43720d1edc0fe, []int{25}
 }
 
 func (m *Event) XXX_Unmarshal(b []byte) error {
@@ -905,6 +1686,20 @@ func (m *Event) GetPayload() []byte {
 	return nil
 }
 
+func (m *Event) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Event) GetTopicSequence() uint64 {
+	if m != nil {
+		return m.TopicSequence
+	}
+	return 0
+}
+
 type PublishRequest struct {
 	Topic                string            `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
 	Labels               map[string]string `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
@@ -918,7 +1713,7 @@ func (m *PublishRequest) Reset()         { *m = PublishRequest{} }
 func (m *PublishRequest) String() string { return proto.CompactTextString(m) }
 func (*PublishRequest) ProtoMessage()    {}
 func (*PublishRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f7e43720d1edc0fe, []int{16}
+	return fileDescriptor_f7e43720d1edc0fe, []int{26}
 }
 
 func (m *PublishRequest) XXX_Unmarshal(b []byte) error {
@@ -960,21 +1755,107 @@ func (m *PublishRequest) GetPayload() []byte {
 	return nil
 }
 
+type PublishBatchRequest struct {
+	Events               []*PublishRequest `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PublishBatchRequest) Reset()         { *m = PublishBatchRequest{} }
+func (m *PublishBatchRequest) String() string { return proto.CompactTextString(m) }
+func (*PublishBatchRequest) ProtoMessage()    {}
+func (*PublishBatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{27}
+}
+
+func (m *PublishBatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublishBatchRequest.Unmarshal(m, b)
+}
+func (m *PublishBatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublishBatchRequest.Marshal(b, m, deterministic)
+}
+func (m *PublishBatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublishBatchRequest.Merge(m, src)
+}
+func (m *PublishBatchRequest) XXX_Size() int {
+	return xxx_messageInfo_PublishBatchRequest.Size(m)
+}
+func (m *PublishBatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublishBatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublishBatchRequest proto.InternalMessageInfo
+
+func (m *PublishBatchRequest) GetEvents() []*PublishRequest {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+type PublishBatchResponse struct {
+	// the published events in request order, payloads and labels are not sent back
+	Events               []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PublishBatchResponse) Reset()         { *m = PublishBatchResponse{} }
+func (m *PublishBatchResponse) String() string { return proto.CompactTextString(m) }
+func (*PublishBatchResponse) ProtoMessage()    {}
+func (*PublishBatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{28}
+}
+
+func (m *PublishBatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublishBatchResponse.Unmarshal(m, b)
+}
+func (m *PublishBatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublishBatchResponse.Marshal(b, m, deterministic)
+}
+func (m *PublishBatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublishBatchResponse.Merge(m, src)
+}
+func (m *PublishBatchResponse) XXX_Size() int {
+	return xxx_messageInfo_PublishBatchResponse.Size(m)
+}
+func (m *PublishBatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublishBatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublishBatchResponse proto.InternalMessageInfo
+
+func (m *PublishBatchResponse) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
 type SubscribeRequest struct {
-	Topic                string               `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
-	Labels               map[string]string    `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	SinceSequence        uint64               `protobuf:"varint,3,opt,name=since_sequence,json=sinceSequence,proto3" json:"since_sequence,omitempty"`
-	SinceCreatedAt       *timestamp.Timestamp `protobuf:"bytes,4,opt,name=since_created_at,json=sinceCreatedAt,proto3" json:"since_created_at,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	// topic or pattern of dot separated tokens, "*" matches one token and a trailing ">" one or more tokens
+	// e.g. orders.*.created or orders.>
+	Topic          string               `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Labels         map[string]string    `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	SinceSequence  uint64               `protobuf:"varint,3,opt,name=since_sequence,json=sinceSequence,proto3" json:"since_sequence,omitempty"`
+	SinceCreatedAt *timestamp.Timestamp `protobuf:"bytes,4,opt,name=since_created_at,json=sinceCreatedAt,proto3" json:"since_created_at,omitempty"`
+	// consumer group, events are distributed among the subscribers of a group and the group resumes
+	// after the last delivered event. since_sequence and since_created_at only apply to new groups.
+	// Delivery is at-most-once: events count as delivered when they are claimed, before they are sent,
+	// so events in flight when a subscriber fails are lost. Use Consume for at-least-once delivery.
+	Group                string   `protobuf:"bytes,5,opt,name=group,proto3" json:"group,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *SubscribeRequest) Reset()         { *m = SubscribeRequest{} }
 func (m *SubscribeRequest) String() string { return proto.CompactTextString(m) }
 func (*SubscribeRequest) ProtoMessage()    {}
 func (*SubscribeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f7e43720d1edc0fe, []int{17}
+	return fileDescriptor_f7e43720d1edc0fe, []int{29}
 }
 
 func (m *SubscribeRequest) XXX_Unmarshal(b []byte) error {
@@ -1023,146 +1904,1347 @@ func (m *SubscribeRequest) GetSinceCreatedAt() *timestamp.Timestamp {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Job)(nil), "api.Job")
-	proto.RegisterMapType((map[string]string)(nil), "api.Job.LabelsEntry")
-	proto.RegisterType((*CronJob)(nil), "api.CronJob")
-	proto.RegisterMapType((map[string]string)(nil), "api.CronJob.LabelsEntry")
-	proto.RegisterType((*CreateJobRequest)(nil), "api.CreateJobRequest")
-	proto.RegisterMapType((map[string]string)(nil), "api.CreateJobRequest.LabelsEntry")
-	proto.RegisterType((*ListenRequest)(nil), "api.ListenRequest")
-	proto.RegisterType((*HeartbeatRequest)(nil), "api.HeartbeatRequest")
-	proto.RegisterType((*GetRequest)(nil), "api.GetRequest")
-	proto.RegisterType((*DeleteRequest)(nil), "api.DeleteRequest")
-	proto.RegisterType((*ListRequest)(nil), "api.ListRequest")
-	proto.RegisterMapType((map[string]string)(nil), "api.ListRequest.LabelsEntry")
-	proto.RegisterType((*CreateCronJobRequest)(nil), "api.CreateCronJobRequest")
+func (m *SubscribeRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+type ExportRequest struct {
+	// topic or pattern of the exported events
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// events created at or after this time, unbounded if unset
+	From *timestamp.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	// events created before this time, unbounded if unset
+	To                   *timestamp.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Labels               map[string]string    `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ExportRequest) Reset()         { *m = ExportRequest{} }
+func (m *ExportRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()    {}
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{30}
+}
+
+func (m *ExportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportRequest.Unmarshal(m, b)
+}
+func (m *ExportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportRequest.Marshal(b, m, deterministic)
+}
+func (m *ExportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportRequest.Merge(m, src)
+}
+func (m *ExportRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportRequest.Size(m)
+}
+func (m *ExportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportRequest proto.InternalMessageInfo
+
+func (m *ExportRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *ExportRequest) GetFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *ExportRequest) GetTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *ExportRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type ReplayRequest struct {
+	// topic or pattern of the replayed events
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// events created at or after this time, unbounded if unset
+	From *timestamp.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	// events created before this time, unbounded if unset
+	To *timestamp.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// topic the copies are published on, it must not be matched by topic
+	TargetTopic          string            `protobuf:"bytes,4,opt,name=target_topic,json=targetTopic,proto3" json:"target_topic,omitempty"`
+	Labels               map[string]string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ReplayRequest) Reset()         { *m = ReplayRequest{} }
+func (m *ReplayRequest) String() string { return proto.CompactTextString(m) }
+func (*ReplayRequest) ProtoMessage()    {}
+func (*ReplayRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{31}
+}
+
+func (m *ReplayRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplayRequest.Unmarshal(m, b)
+}
+func (m *ReplayRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplayRequest.Marshal(b, m, deterministic)
+}
+func (m *ReplayRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplayRequest.Merge(m, src)
+}
+func (m *ReplayRequest) XXX_Size() int {
+	return xxx_messageInfo_ReplayRequest.Size(m)
+}
+func (m *ReplayRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplayRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplayRequest proto.InternalMessageInfo
+
+func (m *ReplayRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *ReplayRequest) GetFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *ReplayRequest) GetTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *ReplayRequest) GetTargetTopic() string {
+	if m != nil {
+		return m.TargetTopic
+	}
+	return ""
+}
+
+func (m *ReplayRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type ReplayResponse struct {
+	// number of replayed events
+	Events uint64 `protobuf:"varint,1,opt,name=events,proto3" json:"events,omitempty"`
+	// sequences of the first and last copy
+	FirstSequence        uint64   `protobuf:"varint,2,opt,name=first_sequence,json=firstSequence,proto3" json:"first_sequence,omitempty"`
+	LastSequence         uint64   `protobuf:"varint,3,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReplayResponse) Reset()         { *m = ReplayResponse{} }
+func (m *ReplayResponse) String() string { return proto.CompactTextString(m) }
+func (*ReplayResponse) ProtoMessage()    {}
+func (*ReplayResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{32}
+}
+
+func (m *ReplayResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplayResponse.Unmarshal(m, b)
+}
+func (m *ReplayResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplayResponse.Marshal(b, m, deterministic)
+}
+func (m *ReplayResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplayResponse.Merge(m, src)
+}
+func (m *ReplayResponse) XXX_Size() int {
+	return xxx_messageInfo_ReplayResponse.Size(m)
+}
+func (m *ReplayResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplayResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplayResponse proto.InternalMessageInfo
+
+func (m *ReplayResponse) GetEvents() uint64 {
+	if m != nil {
+		return m.Events
+	}
+	return 0
+}
+
+func (m *ReplayResponse) GetFirstSequence() uint64 {
+	if m != nil {
+		return m.FirstSequence
+	}
+	return 0
+}
+
+func (m *ReplayResponse) GetLastSequence() uint64 {
+	if m != nil {
+		return m.LastSequence
+	}
+	return 0
+}
+
+type ConsumeRequest struct {
+	// opens the subscription, required in the first message, topic and group must be set
+	Subscribe *SubscribeRequest `protobuf:"bytes,1,opt,name=subscribe,proto3" json:"subscribe,omitempty"`
+	// time after which unacknowledged events are delivered again, defaults to 30s
+	VisibilityTimeout *duration.Duration `protobuf:"bytes,2,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"`
+	// maximum number of unacknowledged events sent to this consumer, defaults to 100
+	MaxInFlight uint32 `protobuf:"varint,3,opt,name=max_in_flight,json=maxInFlight,proto3" json:"max_in_flight,omitempty"`
+	// sequences of processed events
+	Acks                 []uint64 `protobuf:"varint,4,rep,packed,name=acks,proto3" json:"acks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConsumeRequest) Reset()         { *m = ConsumeRequest{} }
+func (m *ConsumeRequest) String() string { return proto.CompactTextString(m) }
+func (*ConsumeRequest) ProtoMessage()    {}
+func (*ConsumeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{33}
+}
+
+func (m *ConsumeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConsumeRequest.Unmarshal(m, b)
+}
+func (m *ConsumeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConsumeRequest.Marshal(b, m, deterministic)
+}
+func (m *ConsumeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConsumeRequest.Merge(m, src)
+}
+func (m *ConsumeRequest) XXX_Size() int {
+	return xxx_messageInfo_ConsumeRequest.Size(m)
+}
+func (m *ConsumeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConsumeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConsumeRequest proto.InternalMessageInfo
+
+func (m *ConsumeRequest) GetSubscribe() *SubscribeRequest {
+	if m != nil {
+		return m.Subscribe
+	}
+	return nil
+}
+
+func (m *ConsumeRequest) GetVisibilityTimeout() *duration.Duration {
+	if m != nil {
+		return m.VisibilityTimeout
+	}
+	return nil
+}
+
+func (m *ConsumeRequest) GetMaxInFlight() uint32 {
+	if m != nil {
+		return m.MaxInFlight
+	}
+	return 0
+}
+
+func (m *ConsumeRequest) GetAcks() []uint64 {
+	if m != nil {
+		return m.Acks
+	}
+	return nil
+}
+
+type EventSchema struct {
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// versions start at 1 and grow with every registered change
+	Version uint32 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	// JSON Schema document
+	Schema string `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	// compatibility checked against the previous version when registering
+	Compatibility        SchemaCompatibility  `protobuf:"varint,4,opt,name=compatibility,proto3,enum=api.SchemaCompatibility" json:"compatibility,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Namespace            string               `protobuf:"bytes,6,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *EventSchema) Reset()         { *m = EventSchema{} }
+func (m *EventSchema) String() string { return proto.CompactTextString(m) }
+func (*EventSchema) ProtoMessage()    {}
+func (*EventSchema) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{34}
+}
+
+func (m *EventSchema) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventSchema.Unmarshal(m, b)
+}
+func (m *EventSchema) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventSchema.Marshal(b, m, deterministic)
+}
+func (m *EventSchema) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventSchema.Merge(m, src)
+}
+func (m *EventSchema) XXX_Size() int {
+	return xxx_messageInfo_EventSchema.Size(m)
+}
+func (m *EventSchema) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventSchema.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventSchema proto.InternalMessageInfo
+
+func (m *EventSchema) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *EventSchema) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *EventSchema) GetSchema() string {
+	if m != nil {
+		return m.Schema
+	}
+	return ""
+}
+
+func (m *EventSchema) GetCompatibility() SchemaCompatibility {
+	if m != nil {
+		return m.Compatibility
+	}
+	return SchemaCompatibility_BACKWARD
+}
+
+func (m *EventSchema) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *EventSchema) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type RegisterSchemaRequest struct {
+	Topic                string              `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Schema               string              `protobuf:"bytes,2,opt,name=schema,proto3" json:"schema,omitempty"`
+	Compatibility        SchemaCompatibility `protobuf:"varint,3,opt,name=compatibility,proto3,enum=api.SchemaCompatibility" json:"compatibility,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *RegisterSchemaRequest) Reset()         { *m = RegisterSchemaRequest{} }
+func (m *RegisterSchemaRequest) String() string { return proto.CompactTextString(m) }
+func (*RegisterSchemaRequest) ProtoMessage()    {}
+func (*RegisterSchemaRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{35}
+}
+
+func (m *RegisterSchemaRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegisterSchemaRequest.Unmarshal(m, b)
+}
+func (m *RegisterSchemaRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegisterSchemaRequest.Marshal(b, m, deterministic)
+}
+func (m *RegisterSchemaRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegisterSchemaRequest.Merge(m, src)
+}
+func (m *RegisterSchemaRequest) XXX_Size() int {
+	return xxx_messageInfo_RegisterSchemaRequest.Size(m)
+}
+func (m *RegisterSchemaRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegisterSchemaRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegisterSchemaRequest proto.InternalMessageInfo
+
+func (m *RegisterSchemaRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *RegisterSchemaRequest) GetSchema() string {
+	if m != nil {
+		return m.Schema
+	}
+	return ""
+}
+
+func (m *RegisterSchemaRequest) GetCompatibility() SchemaCompatibility {
+	if m != nil {
+		return m.Compatibility
+	}
+	return SchemaCompatibility_BACKWARD
+}
+
+type GetSchemaRequest struct {
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// the latest version is returned if unset
+	Version              uint32   `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSchemaRequest) Reset()         { *m = GetSchemaRequest{} }
+func (m *GetSchemaRequest) String() string { return proto.CompactTextString(m) }
+func (*GetSchemaRequest) ProtoMessage()    {}
+func (*GetSchemaRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{36}
+}
+
+func (m *GetSchemaRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSchemaRequest.Unmarshal(m, b)
+}
+func (m *GetSchemaRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSchemaRequest.Marshal(b, m, deterministic)
+}
+func (m *GetSchemaRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSchemaRequest.Merge(m, src)
+}
+func (m *GetSchemaRequest) XXX_Size() int {
+	return xxx_messageInfo_GetSchemaRequest.Size(m)
+}
+func (m *GetSchemaRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSchemaRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSchemaRequest proto.InternalMessageInfo
+
+func (m *GetSchemaRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *GetSchemaRequest) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type ListSchemasRequest struct {
+	// only list schemas of topics starting with this prefix
+	Prefix               string   `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSchemasRequest) Reset()         { *m = ListSchemasRequest{} }
+func (m *ListSchemasRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSchemasRequest) ProtoMessage()    {}
+func (*ListSchemasRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{37}
+}
+
+func (m *ListSchemasRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSchemasRequest.Unmarshal(m, b)
+}
+func (m *ListSchemasRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSchemasRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSchemasRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSchemasRequest.Merge(m, src)
+}
+func (m *ListSchemasRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSchemasRequest.Size(m)
+}
+func (m *ListSchemasRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSchemasRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSchemasRequest proto.InternalMessageInfo
+
+func (m *ListSchemasRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+type Operation struct {
+	// Types that are valid to be assigned to Operation:
+	//	*Operation_CreateJob
+	//	*Operation_DeleteJob
+	//	*Operation_Heartbeat
+	//	*Operation_Publish
+	Operation            isOperation_Operation `protobuf_oneof:"operation"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *Operation) Reset()         { *m = Operation{} }
+func (m *Operation) String() string { return proto.CompactTextString(m) }
+func (*Operation) ProtoMessage()    {}
+func (*Operation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{38}
+}
+
+func (m *Operation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Operation.Unmarshal(m, b)
+}
+func (m *Operation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Operation.Marshal(b, m, deterministic)
+}
+func (m *Operation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Operation.Merge(m, src)
+}
+func (m *Operation) XXX_Size() int {
+	return xxx_messageInfo_Operation.Size(m)
+}
+func (m *Operation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Operation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Operation proto.InternalMessageInfo
+
+type isOperation_Operation interface {
+	isOperation_Operation()
+}
+
+type Operation_CreateJob struct {
+	CreateJob *CreateJobRequest `protobuf:"bytes,1,opt,name=create_job,json=createJob,proto3,oneof"`
+}
+
+type Operation_DeleteJob struct {
+	DeleteJob *DeleteRequest `protobuf:"bytes,2,opt,name=delete_job,json=deleteJob,proto3,oneof"`
+}
+
+type Operation_Heartbeat struct {
+	Heartbeat *HeartbeatRequest `protobuf:"bytes,3,opt,name=heartbeat,proto3,oneof"`
+}
+
+type Operation_Publish struct {
+	Publish *PublishRequest `protobuf:"bytes,4,opt,name=publish,proto3,oneof"`
+}
+
+func (*Operation_CreateJob) isOperation_Operation() {}
+
+func (*Operation_DeleteJob) isOperation_Operation() {}
+
+func (*Operation_Heartbeat) isOperation_Operation() {}
+
+func (*Operation_Publish) isOperation_Operation() {}
+
+func (m *Operation) GetOperation() isOperation_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (m *Operation) GetCreateJob() *CreateJobRequest {
+	if x, ok := m.GetOperation().(*Operation_CreateJob); ok {
+		return x.CreateJob
+	}
+	return nil
+}
+
+func (m *Operation) GetDeleteJob() *DeleteRequest {
+	if x, ok := m.GetOperation().(*Operation_DeleteJob); ok {
+		return x.DeleteJob
+	}
+	return nil
+}
+
+func (m *Operation) GetHeartbeat() *HeartbeatRequest {
+	if x, ok := m.GetOperation().(*Operation_Heartbeat); ok {
+		return x.Heartbeat
+	}
+	return nil
+}
+
+func (m *Operation) GetPublish() *PublishRequest {
+	if x, ok := m.GetOperation().(*Operation_Publish); ok {
+		return x.Publish
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Operation) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Operation_CreateJob)(nil),
+		(*Operation_DeleteJob)(nil),
+		(*Operation_Heartbeat)(nil),
+		(*Operation_Publish)(nil),
+	}
+}
+
+type OperationResult struct {
+	// Types that are valid to be assigned to Result:
+	//	*OperationResult_Job
+	//	*OperationResult_Event
+	Result               isOperationResult_Result `protobuf_oneof:"result"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *OperationResult) Reset()         { *m = OperationResult{} }
+func (m *OperationResult) String() string { return proto.CompactTextString(m) }
+func (*OperationResult) ProtoMessage()    {}
+func (*OperationResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{39}
+}
+
+func (m *OperationResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OperationResult.Unmarshal(m, b)
+}
+func (m *OperationResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OperationResult.Marshal(b, m, deterministic)
+}
+func (m *OperationResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OperationResult.Merge(m, src)
+}
+func (m *OperationResult) XXX_Size() int {
+	return xxx_messageInfo_OperationResult.Size(m)
+}
+func (m *OperationResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_OperationResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OperationResult proto.InternalMessageInfo
+
+type isOperationResult_Result interface {
+	isOperationResult_Result()
+}
+
+type OperationResult_Job struct {
+	Job *Job `protobuf:"bytes,1,opt,name=job,proto3,oneof"`
+}
+
+type OperationResult_Event struct {
+	Event *Event `protobuf:"bytes,2,opt,name=event,proto3,oneof"`
+}
+
+func (*OperationResult_Job) isOperationResult_Result() {}
+
+func (*OperationResult_Event) isOperationResult_Result() {}
+
+func (m *OperationResult) GetResult() isOperationResult_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *OperationResult) GetJob() *Job {
+	if x, ok := m.GetResult().(*OperationResult_Job); ok {
+		return x.Job
+	}
+	return nil
+}
+
+func (m *OperationResult) GetEvent() *Event {
+	if x, ok := m.GetResult().(*OperationResult_Event); ok {
+		return x.Event
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*OperationResult) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*OperationResult_Job)(nil),
+		(*OperationResult_Event)(nil),
+	}
+}
+
+type TransactRequest struct {
+	Operations           []*Operation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *TransactRequest) Reset()         { *m = TransactRequest{} }
+func (m *TransactRequest) String() string { return proto.CompactTextString(m) }
+func (*TransactRequest) ProtoMessage()    {}
+func (*TransactRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{40}
+}
+
+func (m *TransactRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactRequest.Unmarshal(m, b)
+}
+func (m *TransactRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactRequest.Marshal(b, m, deterministic)
+}
+func (m *TransactRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactRequest.Merge(m, src)
+}
+func (m *TransactRequest) XXX_Size() int {
+	return xxx_messageInfo_TransactRequest.Size(m)
+}
+func (m *TransactRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactRequest proto.InternalMessageInfo
+
+func (m *TransactRequest) GetOperations() []*Operation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+type TransactResponse struct {
+	// one result per operation in request order
+	Results              []*OperationResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *TransactResponse) Reset()         { *m = TransactResponse{} }
+func (m *TransactResponse) String() string { return proto.CompactTextString(m) }
+func (*TransactResponse) ProtoMessage()    {}
+func (*TransactResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{41}
+}
+
+func (m *TransactResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactResponse.Unmarshal(m, b)
+}
+func (m *TransactResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactResponse.Marshal(b, m, deterministic)
+}
+func (m *TransactResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactResponse.Merge(m, src)
+}
+func (m *TransactResponse) XXX_Size() int {
+	return xxx_messageInfo_TransactResponse.Size(m)
+}
+func (m *TransactResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactResponse proto.InternalMessageInfo
+
+func (m *TransactResponse) GetResults() []*OperationResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("api.LockMode", LockMode_name, LockMode_value)
+	proto.RegisterEnum("api.SchemaCompatibility", SchemaCompatibility_name, SchemaCompatibility_value)
+	proto.RegisterType((*Job)(nil), "api.Job")
+	proto.RegisterMapType((map[string]string)(nil), "api.Job.LabelsEntry")
+	proto.RegisterType((*CronJob)(nil), "api.CronJob")
+	proto.RegisterMapType((map[string]string)(nil), "api.CronJob.LabelsEntry")
+	proto.RegisterType((*CreateJobRequest)(nil), "api.CreateJobRequest")
+	proto.RegisterMapType((map[string]string)(nil), "api.CreateJobRequest.LabelsEntry")
+	proto.RegisterType((*ListenRequest)(nil), "api.ListenRequest")
+	proto.RegisterType((*HeartbeatRequest)(nil), "api.HeartbeatRequest")
+	proto.RegisterType((*GetRequest)(nil), "api.GetRequest")
+	proto.RegisterType((*DeleteRequest)(nil), "api.DeleteRequest")
+	proto.RegisterType((*ListRequest)(nil), "api.ListRequest")
+	proto.RegisterMapType((map[string]string)(nil), "api.ListRequest.LabelsEntry")
+	proto.RegisterType((*CreateCronJobRequest)(nil), "api.CreateCronJobRequest")
 	proto.RegisterMapType((map[string]string)(nil), "api.CreateCronJobRequest.LabelsEntry")
 	proto.RegisterType((*AquireRequest)(nil), "api.AquireRequest")
 	proto.RegisterType((*AquireResponse)(nil), "api.AquireResponse")
+	proto.RegisterType((*TryAquireResponse)(nil), "api.TryAquireResponse")
+	proto.RegisterType((*LockInfo)(nil), "api.LockInfo")
+	proto.RegisterType((*ListLocksRequest)(nil), "api.ListLocksRequest")
+	proto.RegisterType((*ForceReleaseRequest)(nil), "api.ForceReleaseRequest")
 	proto.RegisterType((*HoldRequest)(nil), "api.HoldRequest")
 	proto.RegisterType((*HoldResponse)(nil), "api.HoldResponse")
 	proto.RegisterType((*ReleaseRequest)(nil), "api.ReleaseRequest")
 	proto.RegisterType((*ReleaseResponse)(nil), "api.ReleaseResponse")
+	proto.RegisterType((*SemaphoreAcquireRequest)(nil), "api.SemaphoreAcquireRequest")
+	proto.RegisterType((*SemaphoreAcquireResponse)(nil), "api.SemaphoreAcquireResponse")
+	proto.RegisterType((*SemaphoreHoldRequest)(nil), "api.SemaphoreHoldRequest")
+	proto.RegisterType((*SemaphoreHoldResponse)(nil), "api.SemaphoreHoldResponse")
+	proto.RegisterType((*SemaphoreReleaseRequest)(nil), "api.SemaphoreReleaseRequest")
+	proto.RegisterType((*SemaphoreReleaseResponse)(nil), "api.SemaphoreReleaseResponse")
 	proto.RegisterType((*Event)(nil), "api.Event")
 	proto.RegisterMapType((map[string]string)(nil), "api.Event.LabelsEntry")
 	proto.RegisterType((*PublishRequest)(nil), "api.PublishRequest")
 	proto.RegisterMapType((map[string]string)(nil), "api.PublishRequest.LabelsEntry")
+	proto.RegisterType((*PublishBatchRequest)(nil), "api.PublishBatchRequest")
+	proto.RegisterType((*PublishBatchResponse)(nil), "api.PublishBatchResponse")
 	proto.RegisterType((*SubscribeRequest)(nil), "api.SubscribeRequest")
 	proto.RegisterMapType((map[string]string)(nil), "api.SubscribeRequest.LabelsEntry")
+	proto.RegisterType((*ExportRequest)(nil), "api.ExportRequest")
+	proto.RegisterMapType((map[string]string)(nil), "api.ExportRequest.LabelsEntry")
+	proto.RegisterType((*ReplayRequest)(nil), "api.ReplayRequest")
+	proto.RegisterMapType((map[string]string)(nil), "api.ReplayRequest.LabelsEntry")
+	proto.RegisterType((*ReplayResponse)(nil), "api.ReplayResponse")
+	proto.RegisterType((*ConsumeRequest)(nil), "api.ConsumeRequest")
+	proto.RegisterType((*EventSchema)(nil), "api.EventSchema")
+	proto.RegisterType((*RegisterSchemaRequest)(nil), "api.RegisterSchemaRequest")
+	proto.RegisterType((*GetSchemaRequest)(nil), "api.GetSchemaRequest")
+	proto.RegisterType((*ListSchemasRequest)(nil), "api.ListSchemasRequest")
+	proto.RegisterType((*Operation)(nil), "api.Operation")
+	proto.RegisterType((*OperationResult)(nil), "api.OperationResult")
+	proto.RegisterType((*TransactRequest)(nil), "api.TransactRequest")
+	proto.RegisterType((*TransactResponse)(nil), "api.TransactResponse")
+}
+
+func init() { proto.RegisterFile("core.proto", fileDescriptor_f7e43720d1edc0fe) }
+
+var fileDescriptor_f7e43720d1edc0fe = []byte{
+	// 2336 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x5a, 0xcf, 0x73, 0xe3, 0x58,
+	0xf1, 0x8f, 0x2c, 0xff, 0x6c, 0xff, 0x18, 0xcf, 0x4b, 0x32, 0xeb, 0x51, 0xed, 0x8f, 0x8c, 0x76,
+	0xf3, 0xfd, 0x86, 0xcc, 0x94, 0x27, 0xeb, 0x2c, 0xb3, 0x3b, 0x03, 0xbb, 0x85, 0xe3, 0x24, 0x93,
+	0x19, 0xc2, 0x0e, 0xa5, 0x64, 0x81, 0x82, 0xa2, 0x5c, 0xb2, 0xfc, 0x92, 0x68, 0x22, 0x4b, 0x1a,
+	0xe9, 0x79, 0x48, 0x2e, 0x70, 0xe2, 0x6f, 0xe0, 0xcc, 0x8d, 0x33, 0x05, 0x07, 0x8e, 0x54, 0xc1,
+	0x52, 0xc5, 0x81, 0x7f, 0x80, 0x1b, 0x07, 0x6e, 0xcb, 0x89, 0x0b, 0x07, 0xaa, 0xa8, 0xf7, 0x43,
+	0xf2, 0x93, 0x22, 0xc7, 0x76, 0x65, 0x0a, 0x6e, 0x7e, 0xad, 0xee, 0xf7, 0xba, 0x3f, 0xdd, 0xaf,
+	0xbb, 0x5f, 0x27, 0x00, 0x96, 0x17, 0xe0, 0xb6, 0x1f, 0x78, 0xc4, 0x43, 0xaa, 0xe9, 0xdb, 0xda,
+	0xbb, 0xa7, 0x9e, 0x77, 0xea, 0xe0, 0x87, 0x8c, 0x34, 0x18, 0x9f, 0x3c, 0x1c, 0x8e, 0x03, 0x93,
+	0xd8, 0x9e, 0xcb, 0x99, 0xb4, 0xf7, 0xd2, 0xdf, 0x89, 0x3d, 0xc2, 0x21, 0x31, 0x47, 0x3e, 0x67,
+	0xd0, 0xff, 0xac, 0x82, 0xfa, 0xdc, 0x1b, 0xa0, 0x06, 0xe4, 0xec, 0x61, 0x4b, 0x59, 0x53, 0x36,
+	0x2a, 0x46, 0xce, 0x1e, 0xa2, 0x15, 0x28, 0xbc, 0x1a, 0xe3, 0x31, 0x6e, 0xe5, 0x18, 0x89, 0x2f,
+	0x10, 0x82, 0x7c, 0xe8, 0x63, 0xab, 0xa5, 0xae, 0x29, 0x1b, 0x35, 0x83, 0xfd, 0xa6, 0x9c, 0x21,
+	0x31, 0x09, 0x6e, 0xe5, 0x19, 0x91, 0x2f, 0xd0, 0x03, 0x28, 0x3a, 0xe6, 0x00, 0x3b, 0x61, 0xab,
+	0xb0, 0xa6, 0x6e, 0x54, 0x3b, 0x2b, 0x6d, 0xd3, 0xb7, 0xdb, 0xcf, 0xbd, 0x41, 0xfb, 0x90, 0x91,
+	0xf7, 0x5c, 0x12, 0x5c, 0x1a, 0x82, 0x07, 0x3d, 0x06, 0xb0, 0x02, 0x6c, 0x12, 0x3c, 0xec, 0x9b,
+	0xa4, 0x55, 0x5c, 0x53, 0x36, 0xaa, 0x1d, 0xad, 0xcd, 0x75, 0x6f, 0x47, 0xba, 0xb7, 0x8f, 0x23,
+	0xdd, 0x8d, 0x8a, 0xe0, 0xee, 0x12, 0x2a, 0x1a, 0x12, 0x33, 0x10, 0xa2, 0xa5, 0xd9, 0xa2, 0x82,
+	0x9b, 0x8b, 0x8e, 0xfd, 0x61, 0x74, 0x6a, 0x79, 0xb6, 0xa8, 0xe0, 0xee, 0x12, 0xf4, 0x0d, 0xa8,
+	0x9e, 0xd8, 0xae, 0x1d, 0x9e, 0x71, 0xd9, 0xca, 0x4c, 0x59, 0x88, 0xd8, 0xbb, 0x04, 0xbd, 0x0d,
+	0x15, 0xd7, 0x1c, 0xe1, 0xd0, 0x37, 0x2d, 0xdc, 0x02, 0x86, 0xef, 0x84, 0xa0, 0x3d, 0x86, 0xaa,
+	0x04, 0x11, 0x6a, 0x82, 0x7a, 0x8e, 0x2f, 0x85, 0x67, 0xe8, 0x4f, 0x0a, 0xf8, 0x6b, 0xd3, 0x99,
+	0xb8, 0x86, 0x2d, 0x9e, 0xe4, 0x3e, 0x51, 0xf4, 0xaf, 0x72, 0x50, 0xea, 0x05, 0x9e, 0x9b, 0xe5,
+	0x50, 0x04, 0x79, 0x7a, 0x86, 0x10, 0x62, 0xbf, 0x27, 0x4e, 0x56, 0xb3, 0x9c, 0x9c, 0x97, 0x9c,
+	0x8c, 0x20, 0x6f, 0x05, 0x9e, 0xdb, 0x2a, 0x70, 0x69, 0xfa, 0x1b, 0x6d, 0xc5, 0x2e, 0x2e, 0x32,
+	0x17, 0xb7, 0x98, 0x8b, 0xc5, 0xf9, 0x73, 0xb8, 0xb9, 0xb4, 0x88, 0x9b, 0x9f, 0x40, 0xd5, 0xc5,
+	0x17, 0xa4, 0x1f, 0x8c, 0xdd, 0x39, 0x9d, 0x45, 0xd9, 0x8d, 0xb1, 0x9b, 0xc6, 0xbb, 0xf2, 0x06,
+	0xf1, 0xfe, 0xb5, 0x02, 0xcd, 0x1e, 0x53, 0xf1, 0xb9, 0x37, 0x30, 0xf0, 0xab, 0x31, 0x0e, 0xc9,
+	0x04, 0x54, 0x25, 0x0b, 0xd4, 0x9c, 0x04, 0xea, 0xe3, 0x18, 0x40, 0x95, 0x01, 0x78, 0x4f, 0x00,
+	0x98, 0xdc, 0x30, 0x0b, 0xc9, 0x9b, 0x28, 0xbd, 0x0e, 0xf5, 0x43, 0x3b, 0x24, 0xd8, 0xbd, 0x56,
+	0x61, 0xfd, 0x47, 0xd0, 0x3c, 0xc0, 0x66, 0x40, 0x06, 0xd8, 0x24, 0x11, 0xe7, 0x2a, 0x14, 0x5f,
+	0x7a, 0x83, 0x7e, 0x1c, 0x57, 0x85, 0x97, 0xde, 0xe0, 0xd9, 0x70, 0x92, 0x01, 0x72, 0x72, 0x06,
+	0xd0, 0xa0, 0x1c, 0xc5, 0x3c, 0x8b, 0xaf, 0xb2, 0x11, 0xaf, 0xf5, 0x2d, 0x80, 0xa7, 0x38, 0xde,
+	0x76, 0x8e, 0x50, 0xd5, 0xb7, 0xa1, 0xbe, 0x8b, 0x1d, 0x4c, 0xf0, 0x22, 0x42, 0xbf, 0x57, 0xa0,
+	0x4a, 0x6d, 0x8d, 0x64, 0xee, 0x40, 0x91, 0x19, 0x17, 0xb6, 0x94, 0x35, 0x75, 0xa3, 0x62, 0x88,
+	0x15, 0xfa, 0x28, 0x76, 0x44, 0x8e, 0x39, 0xe2, 0x6d, 0xe6, 0x08, 0x49, 0x32, 0x33, 0x9a, 0xbf,
+	0x06, 0x4d, 0x7c, 0x61, 0x39, 0xe3, 0x21, 0xee, 0xa7, 0x0c, 0xbd, 0x25, 0xe8, 0xfb, 0x82, 0x7c,
+	0x13, 0x77, 0xfd, 0x4d, 0x81, 0x15, 0x1e, 0x12, 0xe2, 0x66, 0xcd, 0x8c, 0xb3, 0x2b, 0xd7, 0xfc,
+	0xd3, 0x54, 0x9c, 0xad, 0x4b, 0x71, 0x96, 0xdc, 0x34, 0xd3, 0xce, 0x39, 0xf3, 0xc1, 0x4d, 0x8c,
+	0xfc, 0xa3, 0x02, 0xf5, 0xee, 0xab, 0xb1, 0x1d, 0x4c, 0x75, 0xef, 0x37, 0xa1, 0xf6, 0x13, 0xd3,
+	0x26, 0x7d, 0x5a, 0xbf, 0xbc, 0x31, 0x61, 0x5b, 0x54, 0x3b, 0x77, 0xaf, 0x24, 0x80, 0x5d, 0x51,
+	0xff, 0x8c, 0x2a, 0x65, 0x3f, 0xe6, 0xdc, 0xe8, 0x3e, 0xa8, 0x84, 0x38, 0x2d, 0x75, 0x96, 0x10,
+	0xe5, 0x42, 0xf7, 0x20, 0x3f, 0xf2, 0x86, 0xbc, 0x9e, 0x35, 0x3a, 0x75, 0x1e, 0x0b, 0x9e, 0x75,
+	0xfe, 0x1d, 0x6f, 0x88, 0x0d, 0xf6, 0x89, 0x06, 0xd2, 0x99, 0xe7, 0x0c, 0x71, 0x20, 0x00, 0x10,
+	0x2b, 0xfd, 0xef, 0x0a, 0x34, 0x22, 0x3b, 0x42, 0xdf, 0x73, 0x43, 0x9c, 0x55, 0x58, 0x89, 0x77,
+	0x8e, 0xdd, 0x08, 0x04, 0xb6, 0x40, 0xef, 0x43, 0xfd, 0x04, 0xbb, 0x96, 0xed, 0x9e, 0xf6, 0xf9,
+	0x57, 0xaa, 0x6a, 0xde, 0xa8, 0x09, 0xe2, 0x31, 0x63, 0x12, 0x56, 0xe4, 0xe7, 0xb2, 0xe2, 0x31,
+	0x00, 0xbe, 0xf0, 0xed, 0x00, 0x87, 0x34, 0x5f, 0x16, 0x66, 0xe7, 0x4b, 0xc1, 0xdd, 0x25, 0x31,
+	0x00, 0xc5, 0xa9, 0x00, 0xe8, 0x3f, 0x83, 0xdb, 0xc7, 0xc1, 0x65, 0xca, 0x54, 0x0d, 0xca, 0xa6,
+	0xc5, 0x48, 0xdc, 0xe0, 0xb2, 0x11, 0xaf, 0xd1, 0xff, 0x43, 0xde, 0xf1, 0xac, 0x73, 0xe1, 0xb7,
+	0x65, 0xb6, 0x67, 0x52, 0xdc, 0x60, 0x0c, 0x68, 0x3d, 0x86, 0x96, 0x7b, 0x6b, 0x72, 0xfc, 0x33,
+	0xf7, 0xc4, 0x8b, 0x91, 0xfe, 0xad, 0x0a, 0xe5, 0x88, 0x78, 0x05, 0xe3, 0x2b, 0x68, 0xe6, 0x32,
+	0xd0, 0x4c, 0x56, 0x7f, 0x75, 0x91, 0xea, 0x9f, 0xc4, 0x36, 0xbf, 0x08, 0xb6, 0xc2, 0x87, 0x85,
+	0x85, 0x22, 0x71, 0xba, 0x23, 0x50, 0x0b, 0x4a, 0x1c, 0x91, 0x90, 0xd5, 0xd3, 0xba, 0x11, 0x2d,
+	0xa5, 0x18, 0x2d, 0xcb, 0x31, 0x8a, 0xd6, 0xa1, 0xc1, 0x7f, 0xf5, 0xcd, 0xe1, 0x30, 0xc0, 0x61,
+	0x28, 0x4a, 0x62, 0x9d, 0x53, 0xbb, 0x9c, 0x48, 0x3b, 0x9c, 0xc8, 0x79, 0xd4, 0x48, 0x98, 0xdd,
+	0xe1, 0x44, 0xec, 0x5d, 0x42, 0xb5, 0xa2, 0xd7, 0x8f, 0x6a, 0x55, 0xe5, 0x5a, 0x89, 0xa5, 0xbe,
+	0x09, 0x4d, 0x9a, 0x57, 0xa9, 0x15, 0xa1, 0x94, 0x96, 0xfd, 0x00, 0x9f, 0xd8, 0x17, 0xc2, 0x85,
+	0x62, 0xa5, 0xaf, 0xc3, 0xf2, 0xbe, 0x17, 0x58, 0xd8, 0xc0, 0x0e, 0x36, 0xc3, 0x69, 0xa9, 0x41,
+	0xdf, 0x86, 0xea, 0x81, 0xe7, 0x0c, 0xa7, 0x7c, 0xce, 0xbe, 0x70, 0xfa, 0x4f, 0xa1, 0xc6, 0x85,
+	0xa6, 0x5c, 0xd3, 0x79, 0x43, 0x48, 0x8a, 0x03, 0x75, 0x81, 0x38, 0xd0, 0x1f, 0x41, 0xe3, 0x7a,
+	0xb3, 0xa6, 0xe8, 0x7d, 0x0f, 0x6e, 0xc5, 0x72, 0xd9, 0xaa, 0xeb, 0x7f, 0x50, 0xe0, 0xad, 0x23,
+	0x3c, 0x32, 0xfd, 0x33, 0x2f, 0xc0, 0x5d, 0x2b, 0x91, 0x56, 0xa3, 0xf2, 0xa0, 0x48, 0xe5, 0xa1,
+	0x05, 0x25, 0x1f, 0x07, 0x23, 0x9b, 0x84, 0xec, 0xa8, 0xba, 0x11, 0x2d, 0x69, 0x0a, 0x1f, 0x99,
+	0x17, 0xcc, 0xb0, 0xba, 0x41, 0x7f, 0x2e, 0x96, 0x82, 0xd2, 0x39, 0xbb, 0xb0, 0x48, 0xce, 0xd6,
+	0xbf, 0x54, 0xa0, 0x75, 0xd5, 0x0c, 0x61, 0x73, 0x96, 0x1d, 0xd9, 0x99, 0x55, 0xb2, 0x4e, 0x4d,
+	0x5a, 0xf7, 0x5f, 0x4a, 0xa7, 0xfa, 0xb7, 0x60, 0x25, 0xb6, 0x43, 0x0e, 0xd4, 0xb9, 0x6d, 0xd0,
+	0x4f, 0x60, 0x35, 0xb5, 0xc3, 0x35, 0x30, 0x24, 0x35, 0xcd, 0x2d, 0xa2, 0x69, 0x4f, 0x0a, 0x9c,
+	0x54, 0x74, 0xce, 0xaf, 0x6c, 0x1b, 0x5a, 0x57, 0x37, 0x99, 0xae, 0xaf, 0xfe, 0x97, 0x1c, 0x14,
+	0xf6, 0x5e, 0x63, 0x77, 0xca, 0x0d, 0xf0, 0x6d, 0x6b, 0xb2, 0xbf, 0x6f, 0x5b, 0xa8, 0x9d, 0xea,
+	0x66, 0xee, 0xb0, 0xb4, 0xc8, 0x76, 0xc8, 0x6c, 0x5f, 0x34, 0x28, 0x87, 0xd4, 0x08, 0xd7, 0xe2,
+	0x25, 0x3d, 0x6f, 0xc4, 0xeb, 0xd4, 0x83, 0xa4, 0xb0, 0xc8, 0x83, 0x84, 0xc6, 0x95, 0x79, 0xe9,
+	0x78, 0xe6, 0x90, 0xa5, 0xe7, 0x9a, 0x11, 0x2d, 0x93, 0xcf, 0x8d, 0x52, 0xea, 0xb9, 0x41, 0xd3,
+	0x2f, 0xb3, 0xa3, 0x1f, 0x2b, 0x55, 0x66, 0x4a, 0xd5, 0x19, 0xf5, 0x48, 0x10, 0x6f, 0xd2, 0x4c,
+	0xfd, 0x46, 0x81, 0xc6, 0x77, 0xc7, 0x03, 0xc7, 0x0e, 0xcf, 0xa4, 0x5e, 0x91, 0x23, 0xa9, 0xc8,
+	0x48, 0x7e, 0x9c, 0x6a, 0x7b, 0xdf, 0x63, 0x48, 0x26, 0x45, 0x33, 0x21, 0x9d, 0x6a, 0xfb, 0x4d,
+	0xd4, 0xde, 0x81, 0x65, 0x71, 0xf4, 0x8e, 0x49, 0xac, 0x58, 0xf5, 0xfb, 0x50, 0xc4, 0xd4, 0xb7,
+	0xbc, 0x67, 0x8f, 0x5a, 0x87, 0xa4, 0x92, 0x86, 0x60, 0xd1, 0x9f, 0xc0, 0x4a, 0x72, 0x0f, 0x11,
+	0x77, 0x7a, 0x6a, 0x13, 0x98, 0xc4, 0x4c, 0x2c, 0xfb, 0xcb, 0x1c, 0x34, 0x8f, 0xc6, 0x83, 0xd0,
+	0x0a, 0xec, 0x01, 0xbe, 0x1e, 0xb8, 0xc7, 0x29, 0xe0, 0xf8, 0xc3, 0x2d, 0x2d, 0x9c, 0x09, 0xdd,
+	0x3a, 0x34, 0x42, 0xdb, 0xb5, 0xf0, 0xc4, 0xfd, 0xbc, 0xd3, 0xab, 0x33, 0x6a, 0xe4, 0x7e, 0xb4,
+	0x0b, 0x4d, 0xce, 0x26, 0x85, 0xe7, 0xec, 0x3e, 0x83, 0x6f, 0xdd, 0x8b, 0x63, 0x74, 0x05, 0x0a,
+	0xa7, 0x81, 0x37, 0xf6, 0x45, 0x97, 0xca, 0x17, 0x37, 0xf1, 0xd1, 0x3f, 0x15, 0xa8, 0xef, 0x5d,
+	0xf8, 0x5e, 0x40, 0xae, 0x07, 0xa8, 0x0d, 0xf9, 0x93, 0xc0, 0x1b, 0xcd, 0x91, 0x7d, 0x18, 0x1f,
+	0xda, 0x84, 0x1c, 0xf1, 0xe6, 0x28, 0xa0, 0x39, 0xe2, 0xa1, 0x47, 0x31, 0xf8, 0x79, 0x06, 0xfe,
+	0xbb, 0xdc, 0x97, 0xb2, 0x56, 0x6f, 0xfa, 0xc9, 0xfc, 0x8b, 0x1c, 0xd4, 0x0d, 0xec, 0x3b, 0xe6,
+	0xe5, 0xff, 0xce, 0xec, 0x7b, 0x50, 0x23, 0x66, 0x70, 0x8a, 0x49, 0x9f, 0x1f, 0x9c, 0x67, 0x07,
+	0x57, 0x39, 0xed, 0x98, 0x1d, 0xff, 0x28, 0x35, 0x73, 0xe3, 0xc8, 0x24, 0x14, 0x7f, 0xd3, 0xc8,
+	0x10, 0x68, 0x44, 0xfb, 0x8b, 0xab, 0x76, 0x47, 0xba, 0x6a, 0x34, 0xb0, 0xc5, 0x8a, 0x06, 0xfe,
+	0x89, 0x1d, 0x84, 0x64, 0x12, 0xf8, 0xbc, 0xa3, 0xaa, 0x33, 0x6a, 0x1c, 0xf8, 0xef, 0x43, 0xdd,
+	0x31, 0x65, 0x2e, 0xf1, 0x10, 0x72, 0xcc, 0x09, 0x93, 0xfe, 0x27, 0x05, 0x1a, 0x3d, 0xcf, 0x0d,
+	0xc7, 0xa3, 0xf8, 0xa2, 0x6e, 0x43, 0x25, 0x8c, 0xee, 0x1f, 0x3b, 0xb9, 0xda, 0x59, 0xcd, 0xbc,
+	0x95, 0xc6, 0x84, 0x0f, 0x1d, 0x00, 0x7a, 0x6d, 0x87, 0xf6, 0xc0, 0x76, 0x6c, 0x72, 0x39, 0xff,
+	0xd3, 0xf2, 0xf6, 0x44, 0x28, 0x7a, 0x60, 0xea, 0x50, 0x1f, 0x99, 0x17, 0x7d, 0xdb, 0xed, 0x9f,
+	0x38, 0xf6, 0xe9, 0x19, 0x11, 0xbd, 0x46, 0x75, 0x64, 0x5e, 0x3c, 0x73, 0xf7, 0x19, 0x89, 0x16,
+	0x3f, 0xd3, 0x3a, 0xe7, 0x61, 0x9b, 0x37, 0xd8, 0x6f, 0xfd, 0x1f, 0x0a, 0x54, 0x59, 0x1a, 0x3a,
+	0xb2, 0xce, 0xf0, 0xc8, 0x9c, 0x12, 0x57, 0x2d, 0x28, 0xbd, 0xc6, 0x41, 0x68, 0x7b, 0x6e, 0xd4,
+	0xa1, 0x89, 0x25, 0x45, 0x3b, 0x64, 0x92, 0x62, 0x84, 0x27, 0x56, 0xe8, 0x33, 0xa8, 0x5b, 0xde,
+	0xc8, 0x37, 0x89, 0xd0, 0x53, 0x3c, 0x66, 0xf9, 0x88, 0x8e, 0x9f, 0xd5, 0x93, 0xbf, 0x1b, 0x49,
+	0xf6, 0x9b, 0x14, 0xc6, 0x44, 0xf9, 0x2b, 0xa6, 0xca, 0x9f, 0xfe, 0x73, 0x05, 0x56, 0x0d, 0x7c,
+	0x6a, 0x87, 0x04, 0x07, 0x5c, 0x8f, 0xeb, 0xaf, 0xd4, 0xc4, 0xc0, 0xdc, 0xf5, 0x06, 0xaa, 0x0b,
+	0x19, 0xa8, 0xef, 0x40, 0xf3, 0x29, 0x26, 0xf3, 0x68, 0x30, 0x15, 0x7c, 0xfd, 0x01, 0x20, 0xfa,
+	0x96, 0xe1, 0x9b, 0xcc, 0x7c, 0xcd, 0x7c, 0xa5, 0x40, 0xe5, 0x85, 0x8f, 0x79, 0x0c, 0xa1, 0x47,
+	0x11, 0xc0, 0xfd, 0x97, 0xde, 0x20, 0x11, 0xb0, 0xe9, 0xf9, 0xdf, 0xc1, 0x52, 0x84, 0x2e, 0x1d,
+	0xeb, 0x6e, 0x03, 0x0c, 0xb1, 0x83, 0x85, 0x1c, 0x0f, 0x55, 0xc4, 0xe4, 0x12, 0xe3, 0x31, 0x2a,
+	0xc4, 0xf9, 0xa8, 0xd0, 0xd7, 0xa1, 0x72, 0x16, 0xcd, 0xf2, 0x5a, 0xaa, 0x74, 0x56, 0x7a, 0xc2,
+	0x47, 0xc5, 0x62, 0x4e, 0xf4, 0x10, 0x4a, 0x3e, 0xaf, 0xa6, 0xa2, 0xf6, 0x64, 0xd5, 0xde, 0x83,
+	0x25, 0x23, 0xe2, 0xda, 0xa9, 0x42, 0xc5, 0x8b, 0x2c, 0xd4, 0x7f, 0x0c, 0xb7, 0x62, 0x73, 0x0d,
+	0x1c, 0x8e, 0x1d, 0x1a, 0x1a, 0xea, 0xc4, 0xda, 0x72, 0xf4, 0x17, 0x81, 0x83, 0x25, 0x83, 0x92,
+	0x91, 0x0e, 0x05, 0x96, 0x2b, 0x84, 0x55, 0x52, 0x8d, 0x3e, 0x58, 0x32, 0xf8, 0xa7, 0x9d, 0x32,
+	0x14, 0x03, 0xb6, 0x97, 0xde, 0x85, 0x5b, 0xc7, 0x81, 0xe9, 0x86, 0xa6, 0x15, 0xd7, 0xa2, 0x36,
+	0x40, 0x7c, 0x7c, 0x54, 0xe9, 0x1b, 0x6c, 0x97, 0x89, 0x22, 0x12, 0x07, 0x8d, 0x81, 0xc9, 0x16,
+	0x22, 0x7d, 0xb5, 0xa1, 0xc4, 0x0f, 0x88, 0x36, 0x58, 0x49, 0x6d, 0xc0, 0x3e, 0x1a, 0x11, 0xd3,
+	0xe6, 0x3a, 0x1f, 0x43, 0xd0, 0x17, 0x39, 0xaa, 0x43, 0x65, 0xef, 0x07, 0xbd, 0xc3, 0x2f, 0x8e,
+	0x9e, 0x7d, 0x6f, 0xaf, 0xb9, 0x84, 0x00, 0x8a, 0x47, 0x07, 0x5d, 0x63, 0x6f, 0xb7, 0xa9, 0x6c,
+	0xee, 0xc2, 0x72, 0x46, 0x50, 0xa2, 0x1a, 0x94, 0x77, 0xba, 0xbd, 0x6f, 0x7f, 0xbf, 0x6b, 0xec,
+	0x36, 0x97, 0x50, 0x15, 0x4a, 0xfb, 0x2f, 0x0c, 0xb6, 0x50, 0x50, 0x19, 0xf2, 0xfb, 0x5f, 0x1c,
+	0x1e, 0x36, 0x73, 0xf4, 0xd7, 0xe7, 0x2f, 0x3e, 0xdf, 0x6b, 0xaa, 0x9d, 0x7f, 0x29, 0x90, 0x7f,
+	0xee, 0x0d, 0xe8, 0xe8, 0xb1, 0xc8, 0xc3, 0x04, 0x65, 0xc7, 0x8c, 0x16, 0x83, 0x8b, 0x36, 0xa0,
+	0xc8, 0xc7, 0xbd, 0x08, 0xc5, 0x53, 0x4d, 0xec, 0x5e, 0xe1, 0xdb, 0x52, 0xd0, 0x03, 0xa8, 0xc4,
+	0xf1, 0x80, 0xb2, 0xe3, 0x43, 0xda, 0x77, 0x0d, 0xd4, 0xa7, 0x98, 0xa0, 0x5b, 0x8c, 0xf0, 0x14,
+	0x67, 0x70, 0xfc, 0x1f, 0x14, 0x79, 0x4c, 0xa2, 0x8c, 0x00, 0x95, 0xf8, 0x3e, 0x80, 0x3c, 0x55,
+	0x0a, 0x35, 0xd3, 0x53, 0x57, 0x59, 0xbb, 0xce, 0xef, 0x14, 0x28, 0x8b, 0x61, 0x65, 0x88, 0x3e,
+	0x8c, 0xed, 0xbf, 0x3b, 0x75, 0x96, 0xa9, 0xd5, 0xe4, 0xbf, 0x47, 0xa0, 0x0f, 0xa6, 0xe8, 0x9b,
+	0xe4, 0xda, 0xbc, 0x56, 0xe7, 0x24, 0xef, 0xc6, 0x54, 0xbd, 0x13, 0x7c, 0x5b, 0x4a, 0xe7, 0xcb,
+	0x1c, 0x14, 0xd8, 0xc4, 0x83, 0x2a, 0xce, 0xa7, 0x5e, 0x62, 0xff, 0xc4, 0xd0, 0x53, 0xcb, 0x1a,
+	0x8b, 0xa1, 0x8f, 0xa1, 0x12, 0x8f, 0xda, 0x32, 0xa5, 0xf8, 0x03, 0xe8, 0xea, 0x38, 0xee, 0x3e,
+	0xe4, 0xe9, 0x63, 0x51, 0xe8, 0x27, 0xbd, 0x3c, 0xb5, 0xdb, 0x12, 0x45, 0x30, 0x7f, 0x04, 0x25,
+	0xf1, 0x58, 0x43, 0xcb, 0xa2, 0x6d, 0x90, 0xdf, 0x7f, 0xda, 0x4a, 0x92, 0x18, 0xdf, 0x16, 0x0e,
+	0xc1, 0x6a, 0x0c, 0x81, 0x3c, 0xd8, 0xd1, 0x92, 0xb3, 0xbb, 0x2d, 0x05, 0x7d, 0x06, 0x35, 0x79,
+	0xa2, 0x83, 0x78, 0xba, 0xce, 0x18, 0xf2, 0x64, 0x9f, 0xd7, 0xf9, 0xab, 0x02, 0x10, 0x3f, 0x2e,
+	0x43, 0xb4, 0x0f, 0x25, 0x31, 0x18, 0x40, 0x7c, 0x64, 0x3f, 0x65, 0xec, 0xa1, 0xbd, 0x33, 0xe5,
+	0xab, 0x30, 0xe3, 0x53, 0x81, 0xd4, 0xdd, 0x24, 0x9b, 0x0c, 0x99, 0x96, 0xf5, 0x49, 0x88, 0xef,
+	0x4f, 0xb0, 0x4b, 0xa9, 0x91, 0x32, 0xea, 0x9d, 0x29, 0x5f, 0x85, 0x75, 0xff, 0xce, 0x41, 0x71,
+	0x8f, 0x77, 0x4b, 0x9b, 0x50, 0x12, 0x69, 0x16, 0x65, 0x25, 0x5d, 0x4d, 0x4a, 0x8e, 0xa8, 0x07,
+	0x35, 0xf9, 0xd1, 0x23, 0x40, 0xcd, 0x78, 0x4b, 0x69, 0x77, 0x33, 0xbe, 0x08, 0x1b, 0xba, 0x50,
+	0x17, 0xf4, 0x23, 0x12, 0x60, 0x73, 0x94, 0x7d, 0xec, 0xf4, 0x0d, 0x36, 0x14, 0xb4, 0x05, 0x95,
+	0xb8, 0xd9, 0x42, 0xd9, 0xcd, 0x97, 0xac, 0xf7, 0x96, 0x42, 0x93, 0xad, 0x68, 0xe3, 0xc4, 0x71,
+	0xc9, 0xa6, 0x4e, 0xe6, 0xde, 0x50, 0xb6, 0x14, 0x7a, 0x3b, 0x79, 0x9f, 0x2f, 0xee, 0x41, 0xa2,
+	0xe9, 0x4f, 0xed, 0xfd, 0x21, 0x14, 0x79, 0x67, 0x2a, 0x78, 0x13, 0x6d, 0xb0, 0xb6, 0x9c, 0xa0,
+	0x09, 0xfc, 0x7f, 0xa5, 0x40, 0x49, 0x14, 0x73, 0xf4, 0x09, 0x94, 0xa3, 0x36, 0x05, 0x69, 0x82,
+	0x39, 0xa3, 0x6b, 0xd1, 0x9a, 0x93, 0x43, 0xf9, 0x07, 0xd4, 0xe6, 0x89, 0x66, 0x35, 0x4a, 0x34,
+	0xb3, 0xf8, 0xb7, 0xc5, 0x1d, 0x7a, 0x2b, 0xbe, 0x43, 0xc9, 0x86, 0xe2, 0xaa, 0xc8, 0x96, 0xd2,
+	0xe9, 0x41, 0x79, 0xc7, 0xb4, 0xce, 0x07, 0x9e, 0x4b, 0x13, 0x44, 0x39, 0x2a, 0x63, 0x68, 0x45,
+	0xe4, 0x82, 0x44, 0x61, 0xd4, 0x56, 0x53, 0x54, 0x6e, 0xef, 0x4e, 0xe1, 0x87, 0xf4, 0x7f, 0x08,
+	0x06, 0x45, 0xd6, 0xcf, 0x6d, 0xff, 0x67, 0x00, 0xc3, 0x48, 0xdf, 0x80, 0x5d, 0x20, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// JobsClient is the client API for Jobs service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type JobsClient interface {
+	Create(ctx context.Context, in *CreateJobRequest, opts ...grpc.CallOption) (*Job, error)
+	Listen(ctx context.Context, in *ListenRequest, opts ...grpc.CallOption) (Jobs_ListenClient, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Job, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Job, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Job, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (Jobs_ListClient, error)
+}
+
+type jobsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJobsClient(cc grpc.ClientConnInterface) JobsClient {
+	return &jobsClient{cc}
+}
+
+func (c *jobsClient) Create(ctx context.Context, in *CreateJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/api.Jobs/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobsClient) Listen(ctx context.Context, in *ListenRequest, opts ...grpc.CallOption) (Jobs_ListenClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Jobs_serviceDesc.Streams[0], "/api.Jobs/Listen", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobsListenClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Jobs_ListenClient interface {
+	Recv() (*Job, error)
+	grpc.ClientStream
+}
+
+type jobsListenClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobsListenClient) Recv() (*Job, error) {
+	m := new(Job)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobsClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/api.Jobs/Heartbeat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobsClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/api.Jobs/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobsClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/api.Jobs/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobsClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (Jobs_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Jobs_serviceDesc.Streams[1], "/api.Jobs/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobsListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Jobs_ListClient interface {
+	Recv() (*Job, error)
+	grpc.ClientStream
+}
+
+type jobsListClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobsListClient) Recv() (*Job, error) {
+	m := new(Job)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// JobsServer is the server API for Jobs service.
+type JobsServer interface {
+	Create(context.Context, *CreateJobRequest) (*Job, error)
+	Listen(*ListenRequest, Jobs_ListenServer) error
+	Heartbeat(context.Context, *HeartbeatRequest) (*Job, error)
+	Get(context.Context, *GetRequest) (*Job, error)
+	Delete(context.Context, *DeleteRequest) (*Job, error)
+	List(*ListRequest, Jobs_ListServer) error
+}
+
+// UnimplementedJobsServer can be embedded to have forward compatible implementations.
+type UnimplementedJobsServer struct {
+}
+
+func (*UnimplementedJobsServer) Create(ctx context.Context, req *CreateJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (*UnimplementedJobsServer) Listen(req *ListenRequest, srv Jobs_ListenServer) error {
+	return status.Errorf(codes.Unimplemented, "method Listen not implemented")
+}
+func (*UnimplementedJobsServer) Heartbeat(ctx context.Context, req *HeartbeatRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (*UnimplementedJobsServer) Get(ctx context.Context, req *GetRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (*UnimplementedJobsServer) Delete(ctx context.Context, req *DeleteRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedJobsServer) List(req *ListRequest, srv Jobs_ListServer) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+
+func RegisterJobsServer(s *grpc.Server, srv JobsServer) {
+	s.RegisterService(&_Jobs_serviceDesc, srv)
+}
+
+func _Jobs_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobsServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Jobs/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobsServer).Create(ctx, req.(*CreateJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Jobs_Listen_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListenRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobsServer).Listen(m, &jobsListenServer{stream})
+}
+
+type Jobs_ListenServer interface {
+	Send(*Job) error
+	grpc.ServerStream
+}
+
+type jobsListenServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobsListenServer) Send(m *Job) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Jobs_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobsServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Jobs/Heartbeat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobsServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Jobs_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobsServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Jobs/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobsServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Jobs_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobsServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Jobs/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobsServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Jobs_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobsServer).List(m, &jobsListServer{stream})
 }
 
-func init() { proto.RegisterFile("core.proto", fileDescriptor_f7e43720d1edc0fe) }
+type Jobs_ListServer interface {
+	Send(*Job) error
+	grpc.ServerStream
+}
 
-var fileDescriptor_f7e43720d1edc0fe = []byte{
-	// 990 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0xdd, 0x72, 0xdb, 0x44,
-	0x14, 0x9e, 0x95, 0x64, 0xd9, 0x3e, 0x4e, 0x1c, 0xb3, 0xa4, 0x1d, 0xa1, 0x01, 0xe2, 0x7a, 0x1a,
-	0xc6, 0x94, 0x8e, 0x6b, 0xdc, 0xce, 0x40, 0xca, 0x70, 0x11, 0xd2, 0xd2, 0x92, 0xc9, 0x05, 0xa3,
-	0x72, 0x05, 0x17, 0x1e, 0x49, 0xde, 0xb4, 0x6a, 0x15, 0xad, 0xa2, 0x5d, 0x75, 0x9a, 0x47, 0x60,
-	0x78, 0x06, 0xde, 0x80, 0x2b, 0x86, 0x1b, 0x2e, 0x79, 0x02, 0xde, 0x81, 0xb7, 0xe0, 0x8e, 0xd1,
-	0xee, 0x4a, 0x96, 0x14, 0x39, 0x4e, 0xc6, 0xc3, 0x9d, 0xf6, 0xe4, 0x3b, 0x67, 0xbf, 0x3d, 0xdf,
-	0xf9, 0x71, 0x00, 0x7c, 0x9a, 0x90, 0x49, 0x9c, 0x50, 0x4e, 0xb1, 0xee, 0xc6, 0x81, 0xbd, 0xf7,
-	0x92, 0xd2, 0x97, 0x21, 0x79, 0x20, 0x4c, 0x5e, 0x7a, 0xfa, 0x80, 0x07, 0x67, 0x84, 0x71, 0xf7,
-	0x2c, 0x96, 0xa8, 0xd1, 0x6f, 0x3a, 0xe8, 0xc7, 0xd4, 0xc3, 0x7d, 0xd0, 0x82, 0x85, 0x85, 0x86,
-	0x68, 0xdc, 0x75, 0xb4, 0x60, 0x81, 0x77, 0xa1, 0x75, 0x9e, 0x92, 0x94, 0x58, 0x9a, 0x30, 0xc9,
-	0x03, 0xc6, 0x60, 0xb0, 0x98, 0xf8, 0x96, 0x3e, 0x44, 0xe3, 0x2d, 0x47, 0x7c, 0x67, 0x48, 0xc6,
-	0x5d, 0x4e, 0x2c, 0x43, 0x18, 0xe5, 0x01, 0xdf, 0x07, 0x33, 0x74, 0x3d, 0x12, 0x32, 0xab, 0x35,
-	0xd4, 0xc7, 0xbd, 0xd9, 0xee, 0xc4, 0x8d, 0x83, 0xc9, 0x31, 0xf5, 0x26, 0x27, 0xc2, 0xfc, 0x34,
-	0xe2, 0xc9, 0x85, 0xa3, 0x30, 0xf8, 0x00, 0xc0, 0x4f, 0x88, 0xcb, 0xc9, 0x62, 0xee, 0x72, 0xcb,
-	0x1c, 0xa2, 0x71, 0x6f, 0x66, 0x4f, 0x24, 0xf7, 0x49, 0xce, 0x7d, 0xf2, 0x43, 0xce, 0xdd, 0xe9,
-	0x2a, 0xf4, 0x21, 0xcf, 0x5c, 0x19, 0x77, 0x13, 0xe5, 0xda, 0x5e, 0xef, 0xaa, 0xd0, 0xd2, 0x35,
-	0x8d, 0x17, 0xf9, 0xad, 0x9d, 0xf5, 0xae, 0x0a, 0x7d, 0xc8, 0xf1, 0x57, 0xd0, 0x3b, 0x0d, 0xa2,
-	0x80, 0xbd, 0x92, 0xbe, 0xdd, 0xb5, 0xbe, 0x90, 0xc3, 0x0f, 0xb9, 0x7d, 0x00, 0xbd, 0x52, 0x12,
-	0xf0, 0x00, 0xf4, 0x37, 0xe4, 0x42, 0xe5, 0x3e, 0xfb, 0xcc, 0x52, 0xfa, 0xd6, 0x0d, 0x97, 0xc9,
-	0x17, 0x87, 0xc7, 0xda, 0x97, 0x68, 0xf4, 0xb7, 0x06, 0xed, 0xa3, 0x84, 0x46, 0x4d, 0x92, 0x61,
-	0x30, 0x22, 0xf7, 0x2c, 0x77, 0x12, 0xdf, 0x4b, 0x19, 0xf5, 0x26, 0x19, 0x8d, 0x92, 0x8c, 0x18,
-	0x0c, 0x3f, 0xa1, 0x91, 0xd5, 0x92, 0xde, 0xd9, 0x37, 0x9e, 0x16, 0x22, 0x9a, 0x42, 0x44, 0x4b,
-	0x88, 0xa8, 0xee, 0xbf, 0x86, 0x90, 0xed, 0x9b, 0x08, 0xf9, 0x18, 0x7a, 0x11, 0x79, 0xc7, 0xe7,
-	0x49, 0x1a, 0x5d, 0x53, 0x8e, 0x0c, 0xee, 0xa4, 0xd1, 0x66, 0x19, 0xfd, 0x1d, 0xc1, 0xe0, 0x48,
-	0x90, 0x38, 0xa6, 0x9e, 0x43, 0xce, 0x53, 0xc2, 0xf8, 0x32, 0x6d, 0xa8, 0x29, 0x6d, 0x5a, 0x29,
-	0x6d, 0x07, 0x45, 0x8a, 0x74, 0x91, 0xa2, 0x3b, 0x2a, 0x45, 0xd5, 0x80, 0x4d, 0xb9, 0xda, 0x84,
-	0xf4, 0x3e, 0x6c, 0x9f, 0x04, 0x8c, 0x93, 0xe8, 0x4a, 0xc2, 0xa3, 0x9f, 0x60, 0xf0, 0x9c, 0xb8,
-	0x09, 0xf7, 0x88, 0xcb, 0x73, 0xe4, 0x2d, 0x30, 0x5f, 0x53, 0x6f, 0x5e, 0x54, 0x4e, 0xeb, 0x35,
-	0xf5, 0xbe, 0x5b, 0x2c, 0xbb, 0x58, 0x2b, 0x77, 0xb1, 0x0d, 0x9d, 0xbc, 0x6e, 0x45, 0x05, 0x75,
-	0x9c, 0xe2, 0x3c, 0x9a, 0x02, 0x3c, 0x23, 0x45, 0xd8, 0x6b, 0x14, 0xe3, 0xe8, 0x21, 0x6c, 0x3f,
-	0x21, 0x21, 0xe1, 0xe4, 0x26, 0x4e, 0x7f, 0x21, 0xe8, 0x65, 0x6f, 0xcd, 0x7d, 0x6e, 0x83, 0x29,
-	0x1e, 0xc7, 0x2c, 0x34, 0xd4, 0xc7, 0x5d, 0x47, 0x9d, 0xf0, 0xa3, 0x42, 0x08, 0x4d, 0x08, 0xf1,
-	0xa1, 0x10, 0xa2, 0xe4, 0xd9, 0x58, 0xaf, 0x9f, 0xc2, 0x80, 0xbc, 0xf3, 0xc3, 0x74, 0x41, 0xe6,
-	0xb5, 0x87, 0xee, 0x28, 0xfb, 0xb7, 0xca, 0xbc, 0x89, 0x5c, 0xff, 0x20, 0xd8, 0x95, 0x25, 0xa1,
-	0x7a, 0x67, 0x6d, 0x9d, 0x5d, 0x6a, 0xe4, 0xaf, 0x6b, 0x75, 0xb6, 0x5f, 0xaa, 0xb3, 0x6a, 0xd0,
-	0xc6, 0x77, 0x5e, 0xb3, 0xe3, 0x37, 0x79, 0xe4, 0x1e, 0x6c, 0x1f, 0x9e, 0xa7, 0x41, 0xb2, 0x4a,
-	0xdd, 0xd1, 0x10, 0xfa, 0x39, 0x80, 0xc5, 0x34, 0x62, 0xe4, 0x12, 0xe2, 0x23, 0xe8, 0x3d, 0xa7,
-	0xe1, 0x62, 0x55, 0x80, 0x8f, 0x61, 0x4b, 0xfe, 0x79, 0x85, 0xfb, 0x10, 0xfa, 0x0e, 0x09, 0x89,
-	0xcb, 0x56, 0x52, 0xb8, 0x03, 0x3b, 0x05, 0x62, 0x45, 0x90, 0x9f, 0x35, 0x68, 0x3d, 0x7d, 0x4b,
-	0x22, 0xde, 0xb4, 0x12, 0x39, 0x8d, 0x03, 0x3f, 0x7f, 0xba, 0x38, 0xe0, 0x49, 0x4d, 0x98, 0xdb,
-	0x42, 0x18, 0x11, 0xa1, 0x51, 0x09, 0x1b, 0x3a, 0x2c, 0x63, 0x17, 0xf9, 0x72, 0x63, 0x1a, 0x4e,
-	0x71, 0xae, 0x4d, 0xcf, 0xd6, 0x4d, 0xa6, 0xa7, 0x05, 0xed, 0xd8, 0xbd, 0x08, 0xa9, 0xbb, 0x10,
-	0xeb, 0x73, 0xcb, 0xc9, 0x8f, 0x9b, 0x48, 0xfa, 0x07, 0x82, 0xfe, 0xf7, 0xa9, 0x17, 0x06, 0xec,
-	0x55, 0xa9, 0x62, 0x65, 0x12, 0x50, 0x39, 0x09, 0x5f, 0xd4, 0x9a, 0x6f, 0x4f, 0x24, 0xa1, 0xea,
-	0xda, 0x98, 0x8d, 0xff, 0x85, 0xf6, 0x2f, 0x1a, 0x0c, 0x5e, 0xa4, 0x1e, 0xf3, 0x93, 0xc0, 0x23,
-	0x57, 0x13, 0x3f, 0xa8, 0x11, 0x97, 0xe3, 0xbb, 0xee, 0xdc, 0x48, 0x7d, 0x1f, 0xfa, 0x2c, 0x88,
-	0x7c, 0x32, 0x2f, 0xe4, 0xd4, 0x85, 0x9c, 0xdb, 0xc2, 0xfa, 0x22, 0xd7, 0xf4, 0x09, 0x0c, 0x24,
-	0xac, 0xa4, 0xac, 0xb1, 0x56, 0x59, 0x19, 0xfa, 0x28, 0x97, 0x77, 0x83, 0x6c, 0xcc, 0xfe, 0x45,
-	0x60, 0x1c, 0x53, 0x2f, 0x9b, 0x75, 0xa6, 0x0c, 0x88, 0x6f, 0x35, 0x2e, 0x29, 0xbb, 0x93, 0xff,
-	0x46, 0xc3, 0x63, 0x30, 0xe5, 0x7e, 0xc1, 0xb8, 0x18, 0xa3, 0xc5, 0xb2, 0x59, 0xe2, 0xa6, 0x08,
-	0xdf, 0x87, 0x6e, 0xb1, 0x62, 0x54, 0xdc, 0xfa, 0xca, 0x29, 0xc5, 0x1d, 0x82, 0xfe, 0x8c, 0x70,
-	0xbc, 0x23, 0x0c, 0xcb, 0xed, 0x51, 0x42, 0x7c, 0x02, 0xa6, 0xdc, 0x11, 0xea, 0xe6, 0xca, 0xc2,
-	0x28, 0xe1, 0xee, 0x82, 0x91, 0x91, 0xc2, 0x83, 0xfa, 0x98, 0x2f, 0xb3, 0x9b, 0xfd, 0x89, 0xa0,
-	0xa3, 0xa6, 0x23, 0xc3, 0x9f, 0x17, 0xef, 0xff, 0x60, 0xe5, 0xf0, 0xb4, 0xb7, 0xca, 0x3f, 0x71,
-	0xf0, 0xdd, 0x15, 0x7c, 0xab, 0xa8, 0x7b, 0x57, 0x72, 0xae, 0x62, 0xc7, 0x2b, 0x79, 0x57, 0x70,
-	0x53, 0x34, 0xfb, 0x15, 0x41, 0xeb, 0x84, 0xfa, 0x6f, 0x04, 0x71, 0x39, 0x38, 0x55, 0xfc, 0xca,
-	0x98, 0xb5, 0xdf, 0xaf, 0xd8, 0xd4, 0x54, 0xfb, 0x0c, 0x8c, 0x6c, 0x54, 0xaa, 0x6b, 0x4a, 0x43,
-	0xd5, 0x7e, 0xaf, 0x64, 0x51, 0xe0, 0x47, 0xd0, 0x56, 0x53, 0x11, 0xcb, 0x60, 0xd5, 0x29, 0x6a,
-	0xef, 0x56, 0x8d, 0xd2, 0x6b, 0x76, 0x0a, 0xa6, 0x98, 0x72, 0x0c, 0xdf, 0x83, 0xb6, 0x6a, 0x75,
-	0xe5, 0x5f, 0x6d, 0x7c, 0x1b, 0x96, 0x23, 0x11, 0x4f, 0xa1, 0x5b, 0x74, 0x97, 0xaa, 0x97, 0x7a,
-	0xb7, 0x95, 0xf1, 0x53, 0xf4, 0x4d, 0xeb, 0xc7, 0xec, 0x3f, 0x19, 0xcf, 0x14, 0x5d, 0xf2, 0xf0,
-	0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x1a, 0xf2, 0x6b, 0xec, 0xe3, 0x0c, 0x00, 0x00,
+type jobsListServer struct {
+	grpc.ServerStream
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (x *jobsListServer) Send(m *Job) error {
+	return x.ServerStream.SendMsg(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var _Jobs_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Jobs",
+	HandlerType: (*JobsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _Jobs_Create_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Jobs_Heartbeat_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Jobs_Get_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Jobs_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Listen",
+			Handler:       _Jobs_Listen_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "List",
+			Handler:       _Jobs_List_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "core.proto",
+}
 
-// JobsClient is the client API for Jobs service.
+// CronJobsClient is the client API for CronJobs service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type JobsClient interface {
-	Create(ctx context.Context, in *CreateJobRequest, opts ...grpc.CallOption) (*Job, error)
-	Listen(ctx context.Context, in *ListenRequest, opts ...grpc.CallOption) (Jobs_ListenClient, error)
-	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Job, error)
-	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Job, error)
-	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Job, error)
-	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (Jobs_ListClient, error)
+type CronJobsClient interface {
+	Create(ctx context.Context, in *CreateCronJobRequest, opts ...grpc.CallOption) (*CronJob, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*CronJob, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*CronJob, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (CronJobs_ListClient, error)
 }
 
-type jobsClient struct {
-	cc *grpc.ClientConn
+type cronJobsClient struct {
+	cc grpc.ClientConnInterface
 }
 
-func NewJobsClient(cc *grpc.ClientConn) JobsClient {
-	return &jobsClient{cc}
+func NewCronJobsClient(cc grpc.ClientConnInterface) CronJobsClient {
+	return &cronJobsClient{cc}
 }
 
-func (c *jobsClient) Create(ctx context.Context, in *CreateJobRequest, opts ...grpc.CallOption) (*Job, error) {
-	out := new(Job)
-	err := c.cc.Invoke(ctx, "/api.Jobs/Create", in, out, opts...)
+func (c *cronJobsClient) Create(ctx context.Context, in *CreateCronJobRequest, opts ...grpc.CallOption) (*CronJob, error) {
+	out := new(CronJob)
+	err := c.cc.Invoke(ctx, "/api.CronJobs/Create", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *jobsClient) Listen(ctx context.Context, in *ListenRequest, opts ...grpc.CallOption) (Jobs_ListenClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Jobs_serviceDesc.Streams[0], "/api.Jobs/Listen", opts...)
+func (c *cronJobsClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*CronJob, error) {
+	out := new(CronJob)
+	err := c.cc.Invoke(ctx, "/api.CronJobs/Get", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &jobsListenClient{stream}
+	return out, nil
+}
+
+func (c *cronJobsClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*CronJob, error) {
+	out := new(CronJob)
+	err := c.cc.Invoke(ctx, "/api.CronJobs/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cronJobsClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (CronJobs_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CronJobs_serviceDesc.Streams[0], "/api.CronJobs/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cronJobsListClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -1172,56 +3254,218 @@ func (c *jobsClient) Listen(ctx context.Context, in *ListenRequest, opts ...grpc
 	return x, nil
 }
 
-type Jobs_ListenClient interface {
-	Recv() (*Job, error)
-	grpc.ClientStream
+type CronJobs_ListClient interface {
+	Recv() (*CronJob, error)
+	grpc.ClientStream
+}
+
+type cronJobsListClient struct {
+	grpc.ClientStream
+}
+
+func (x *cronJobsListClient) Recv() (*CronJob, error) {
+	m := new(CronJob)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CronJobsServer is the server API for CronJobs service.
+type CronJobsServer interface {
+	Create(context.Context, *CreateCronJobRequest) (*CronJob, error)
+	Get(context.Context, *GetRequest) (*CronJob, error)
+	Delete(context.Context, *DeleteRequest) (*CronJob, error)
+	List(*ListRequest, CronJobs_ListServer) error
+}
+
+// UnimplementedCronJobsServer can be embedded to have forward compatible implementations.
+type UnimplementedCronJobsServer struct {
+}
+
+func (*UnimplementedCronJobsServer) Create(ctx context.Context, req *CreateCronJobRequest) (*CronJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (*UnimplementedCronJobsServer) Get(ctx context.Context, req *GetRequest) (*CronJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (*UnimplementedCronJobsServer) Delete(ctx context.Context, req *DeleteRequest) (*CronJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedCronJobsServer) List(req *ListRequest, srv CronJobs_ListServer) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+
+func RegisterCronJobsServer(s *grpc.Server, srv CronJobsServer) {
+	s.RegisterService(&_CronJobs_serviceDesc, srv)
+}
+
+func _CronJobs_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCronJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronJobsServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CronJobs/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronJobsServer).Create(ctx, req.(*CreateCronJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronJobs_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronJobsServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CronJobs/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronJobsServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronJobs_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronJobsServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.CronJobs/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronJobsServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronJobs_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CronJobsServer).List(m, &cronJobsListServer{stream})
+}
+
+type CronJobs_ListServer interface {
+	Send(*CronJob) error
+	grpc.ServerStream
+}
+
+type cronJobsListServer struct {
+	grpc.ServerStream
+}
+
+func (x *cronJobsListServer) Send(m *CronJob) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _CronJobs_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.CronJobs",
+	HandlerType: (*CronJobsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _CronJobs_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _CronJobs_Get_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _CronJobs_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _CronJobs_List_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "core.proto",
+}
+
+// LocksClient is the client API for Locks service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LocksClient interface {
+	Aquire(ctx context.Context, in *AquireRequest, opts ...grpc.CallOption) (*AquireResponse, error)
+	TryAquire(ctx context.Context, in *AquireRequest, opts ...grpc.CallOption) (*TryAquireResponse, error)
+	Hold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*HoldResponse, error)
+	Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error)
+	// lists held locks and locks with waiters
+	List(ctx context.Context, in *ListLocksRequest, opts ...grpc.CallOption) (Locks_ListClient, error)
+	// releases a lock regardless of its holders
+	ForceRelease(ctx context.Context, in *ForceReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error)
+}
+
+type locksClient struct {
+	cc grpc.ClientConnInterface
 }
 
-type jobsListenClient struct {
-	grpc.ClientStream
+func NewLocksClient(cc grpc.ClientConnInterface) LocksClient {
+	return &locksClient{cc}
 }
 
-func (x *jobsListenClient) Recv() (*Job, error) {
-	m := new(Job)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *locksClient) Aquire(ctx context.Context, in *AquireRequest, opts ...grpc.CallOption) (*AquireResponse, error) {
+	out := new(AquireResponse)
+	err := c.cc.Invoke(ctx, "/api.Locks/Aquire", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *jobsClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Job, error) {
-	out := new(Job)
-	err := c.cc.Invoke(ctx, "/api.Jobs/Heartbeat", in, out, opts...)
+func (c *locksClient) TryAquire(ctx context.Context, in *AquireRequest, opts ...grpc.CallOption) (*TryAquireResponse, error) {
+	out := new(TryAquireResponse)
+	err := c.cc.Invoke(ctx, "/api.Locks/TryAquire", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *jobsClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Job, error) {
-	out := new(Job)
-	err := c.cc.Invoke(ctx, "/api.Jobs/Get", in, out, opts...)
+func (c *locksClient) Hold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*HoldResponse, error) {
+	out := new(HoldResponse)
+	err := c.cc.Invoke(ctx, "/api.Locks/Hold", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *jobsClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Job, error) {
-	out := new(Job)
-	err := c.cc.Invoke(ctx, "/api.Jobs/Delete", in, out, opts...)
+func (c *locksClient) Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error) {
+	out := new(ReleaseResponse)
+	err := c.cc.Invoke(ctx, "/api.Locks/Release", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *jobsClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (Jobs_ListClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Jobs_serviceDesc.Streams[1], "/api.Jobs/List", opts...)
+func (c *locksClient) List(ctx context.Context, in *ListLocksRequest, opts ...grpc.CallOption) (Locks_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Locks_serviceDesc.Streams[0], "/api.Locks/List", opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &jobsListClient{stream}
+	x := &locksListClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -1231,261 +3475,445 @@ func (c *jobsClient) List(ctx context.Context, in *ListRequest, opts ...grpc.Cal
 	return x, nil
 }
 
-type Jobs_ListClient interface {
-	Recv() (*Job, error)
+type Locks_ListClient interface {
+	Recv() (*LockInfo, error)
 	grpc.ClientStream
 }
 
-type jobsListClient struct {
+type locksListClient struct {
 	grpc.ClientStream
 }
 
-func (x *jobsListClient) Recv() (*Job, error) {
-	m := new(Job)
+func (x *locksListClient) Recv() (*LockInfo, error) {
+	m := new(LockInfo)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-// JobsServer is the server API for Jobs service.
-type JobsServer interface {
-	Create(context.Context, *CreateJobRequest) (*Job, error)
-	Listen(*ListenRequest, Jobs_ListenServer) error
-	Heartbeat(context.Context, *HeartbeatRequest) (*Job, error)
-	Get(context.Context, *GetRequest) (*Job, error)
-	Delete(context.Context, *DeleteRequest) (*Job, error)
-	List(*ListRequest, Jobs_ListServer) error
+func (c *locksClient) ForceRelease(ctx context.Context, in *ForceReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error) {
+	out := new(ReleaseResponse)
+	err := c.cc.Invoke(ctx, "/api.Locks/ForceRelease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// UnimplementedJobsServer can be embedded to have forward compatible implementations.
-type UnimplementedJobsServer struct {
+// LocksServer is the server API for Locks service.
+type LocksServer interface {
+	Aquire(context.Context, *AquireRequest) (*AquireResponse, error)
+	TryAquire(context.Context, *AquireRequest) (*TryAquireResponse, error)
+	Hold(context.Context, *HoldRequest) (*HoldResponse, error)
+	Release(context.Context, *ReleaseRequest) (*ReleaseResponse, error)
+	// lists held locks and locks with waiters
+	List(*ListLocksRequest, Locks_ListServer) error
+	// releases a lock regardless of its holders
+	ForceRelease(context.Context, *ForceReleaseRequest) (*ReleaseResponse, error)
 }
 
-func (*UnimplementedJobsServer) Create(ctx context.Context, req *CreateJobRequest) (*Job, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+// UnimplementedLocksServer can be embedded to have forward compatible implementations.
+type UnimplementedLocksServer struct {
 }
-func (*UnimplementedJobsServer) Listen(req *ListenRequest, srv Jobs_ListenServer) error {
-	return status.Errorf(codes.Unimplemented, "method Listen not implemented")
+
+func (*UnimplementedLocksServer) Aquire(ctx context.Context, req *AquireRequest) (*AquireResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Aquire not implemented")
 }
-func (*UnimplementedJobsServer) Heartbeat(ctx context.Context, req *HeartbeatRequest) (*Job, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+func (*UnimplementedLocksServer) TryAquire(ctx context.Context, req *AquireRequest) (*TryAquireResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TryAquire not implemented")
 }
-func (*UnimplementedJobsServer) Get(ctx context.Context, req *GetRequest) (*Job, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+func (*UnimplementedLocksServer) Hold(ctx context.Context, req *HoldRequest) (*HoldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Hold not implemented")
 }
-func (*UnimplementedJobsServer) Delete(ctx context.Context, req *DeleteRequest) (*Job, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+func (*UnimplementedLocksServer) Release(ctx context.Context, req *ReleaseRequest) (*ReleaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Release not implemented")
 }
-func (*UnimplementedJobsServer) List(req *ListRequest, srv Jobs_ListServer) error {
+func (*UnimplementedLocksServer) List(req *ListLocksRequest, srv Locks_ListServer) error {
 	return status.Errorf(codes.Unimplemented, "method List not implemented")
 }
+func (*UnimplementedLocksServer) ForceRelease(ctx context.Context, req *ForceReleaseRequest) (*ReleaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceRelease not implemented")
+}
 
-func RegisterJobsServer(s *grpc.Server, srv JobsServer) {
-	s.RegisterService(&_Jobs_serviceDesc, srv)
+func RegisterLocksServer(s *grpc.Server, srv LocksServer) {
+	s.RegisterService(&_Locks_serviceDesc, srv)
 }
 
-func _Jobs_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateJobRequest)
+func _Locks_Aquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AquireRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(JobsServer).Create(ctx, in)
+		return srv.(LocksServer).Aquire(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Jobs/Create",
+		FullMethod: "/api.Locks/Aquire",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(JobsServer).Create(ctx, req.(*CreateJobRequest))
+		return srv.(LocksServer).Aquire(ctx, req.(*AquireRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Jobs_Listen_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(ListenRequest)
+func _Locks_TryAquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AquireRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocksServer).TryAquire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Locks/TryAquire",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocksServer).TryAquire(ctx, req.(*AquireRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locks_Hold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocksServer).Hold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Locks/Hold",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocksServer).Hold(ctx, req.(*HoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locks_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocksServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Locks/Release",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocksServer).Release(ctx, req.(*ReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locks_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListLocksRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(JobsServer).Listen(m, &jobsListenServer{stream})
+	return srv.(LocksServer).List(m, &locksListServer{stream})
 }
 
-type Jobs_ListenServer interface {
-	Send(*Job) error
+type Locks_ListServer interface {
+	Send(*LockInfo) error
 	grpc.ServerStream
 }
 
-type jobsListenServer struct {
+type locksListServer struct {
 	grpc.ServerStream
 }
 
-func (x *jobsListenServer) Send(m *Job) error {
+func (x *locksListServer) Send(m *LockInfo) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func _Jobs_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HeartbeatRequest)
+func _Locks_ForceRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceReleaseRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(JobsServer).Heartbeat(ctx, in)
+		return srv.(LocksServer).ForceRelease(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Jobs/Heartbeat",
+		FullMethod: "/api.Locks/ForceRelease",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(JobsServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+		return srv.(LocksServer).ForceRelease(ctx, req.(*ForceReleaseRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Jobs_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetRequest)
+var _Locks_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Locks",
+	HandlerType: (*LocksServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Aquire",
+			Handler:    _Locks_Aquire_Handler,
+		},
+		{
+			MethodName: "TryAquire",
+			Handler:    _Locks_TryAquire_Handler,
+		},
+		{
+			MethodName: "Hold",
+			Handler:    _Locks_Hold_Handler,
+		},
+		{
+			MethodName: "Release",
+			Handler:    _Locks_Release_Handler,
+		},
+		{
+			MethodName: "ForceRelease",
+			Handler:    _Locks_ForceRelease_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _Locks_List_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "core.proto",
+}
+
+// SemaphoresClient is the client API for Semaphores service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SemaphoresClient interface {
+	Acquire(ctx context.Context, in *SemaphoreAcquireRequest, opts ...grpc.CallOption) (*SemaphoreAcquireResponse, error)
+	Hold(ctx context.Context, in *SemaphoreHoldRequest, opts ...grpc.CallOption) (*SemaphoreHoldResponse, error)
+	Release(ctx context.Context, in *SemaphoreReleaseRequest, opts ...grpc.CallOption) (*SemaphoreReleaseResponse, error)
+}
+
+type semaphoresClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSemaphoresClient(cc grpc.ClientConnInterface) SemaphoresClient {
+	return &semaphoresClient{cc}
+}
+
+func (c *semaphoresClient) Acquire(ctx context.Context, in *SemaphoreAcquireRequest, opts ...grpc.CallOption) (*SemaphoreAcquireResponse, error) {
+	out := new(SemaphoreAcquireResponse)
+	err := c.cc.Invoke(ctx, "/api.Semaphores/Acquire", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semaphoresClient) Hold(ctx context.Context, in *SemaphoreHoldRequest, opts ...grpc.CallOption) (*SemaphoreHoldResponse, error) {
+	out := new(SemaphoreHoldResponse)
+	err := c.cc.Invoke(ctx, "/api.Semaphores/Hold", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semaphoresClient) Release(ctx context.Context, in *SemaphoreReleaseRequest, opts ...grpc.CallOption) (*SemaphoreReleaseResponse, error) {
+	out := new(SemaphoreReleaseResponse)
+	err := c.cc.Invoke(ctx, "/api.Semaphores/Release", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SemaphoresServer is the server API for Semaphores service.
+type SemaphoresServer interface {
+	Acquire(context.Context, *SemaphoreAcquireRequest) (*SemaphoreAcquireResponse, error)
+	Hold(context.Context, *SemaphoreHoldRequest) (*SemaphoreHoldResponse, error)
+	Release(context.Context, *SemaphoreReleaseRequest) (*SemaphoreReleaseResponse, error)
+}
+
+// UnimplementedSemaphoresServer can be embedded to have forward compatible implementations.
+type UnimplementedSemaphoresServer struct {
+}
+
+func (*UnimplementedSemaphoresServer) Acquire(ctx context.Context, req *SemaphoreAcquireRequest) (*SemaphoreAcquireResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Acquire not implemented")
+}
+func (*UnimplementedSemaphoresServer) Hold(ctx context.Context, req *SemaphoreHoldRequest) (*SemaphoreHoldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Hold not implemented")
+}
+func (*UnimplementedSemaphoresServer) Release(ctx context.Context, req *SemaphoreReleaseRequest) (*SemaphoreReleaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Release not implemented")
+}
+
+func RegisterSemaphoresServer(s *grpc.Server, srv SemaphoresServer) {
+	s.RegisterService(&_Semaphores_serviceDesc, srv)
+}
+
+func _Semaphores_Acquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SemaphoreAcquireRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(JobsServer).Get(ctx, in)
+		return srv.(SemaphoresServer).Acquire(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Jobs/Get",
+		FullMethod: "/api.Semaphores/Acquire",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(JobsServer).Get(ctx, req.(*GetRequest))
+		return srv.(SemaphoresServer).Acquire(ctx, req.(*SemaphoreAcquireRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Jobs_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteRequest)
+func _Semaphores_Hold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SemaphoreHoldRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(JobsServer).Delete(ctx, in)
+		return srv.(SemaphoresServer).Hold(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Jobs/Delete",
+		FullMethod: "/api.Semaphores/Hold",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(JobsServer).Delete(ctx, req.(*DeleteRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
-func _Jobs_List_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(ListRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(JobsServer).List(m, &jobsListServer{stream})
-}
-
-type Jobs_ListServer interface {
-	Send(*Job) error
-	grpc.ServerStream
-}
-
-type jobsListServer struct {
-	grpc.ServerStream
+		return srv.(SemaphoresServer).Hold(ctx, req.(*SemaphoreHoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *jobsListServer) Send(m *Job) error {
-	return x.ServerStream.SendMsg(m)
+func _Semaphores_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SemaphoreReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemaphoresServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Semaphores/Release",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemaphoresServer).Release(ctx, req.(*SemaphoreReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-var _Jobs_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "api.Jobs",
-	HandlerType: (*JobsServer)(nil),
+var _Semaphores_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Semaphores",
+	HandlerType: (*SemaphoresServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Create",
-			Handler:    _Jobs_Create_Handler,
-		},
-		{
-			MethodName: "Heartbeat",
-			Handler:    _Jobs_Heartbeat_Handler,
-		},
-		{
-			MethodName: "Get",
-			Handler:    _Jobs_Get_Handler,
-		},
-		{
-			MethodName: "Delete",
-			Handler:    _Jobs_Delete_Handler,
+			MethodName: "Acquire",
+			Handler:    _Semaphores_Acquire_Handler,
 		},
-	},
-	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "Listen",
-			Handler:       _Jobs_Listen_Handler,
-			ServerStreams: true,
+			MethodName: "Hold",
+			Handler:    _Semaphores_Hold_Handler,
 		},
 		{
-			StreamName:    "List",
-			Handler:       _Jobs_List_Handler,
-			ServerStreams: true,
+			MethodName: "Release",
+			Handler:    _Semaphores_Release_Handler,
 		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "core.proto",
 }
 
-// CronJobsClient is the client API for CronJobs service.
+// EventsClient is the client API for Events service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type CronJobsClient interface {
-	Create(ctx context.Context, in *CreateCronJobRequest, opts ...grpc.CallOption) (*CronJob, error)
-	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*CronJob, error)
-	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*CronJob, error)
-	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (CronJobs_ListClient, error)
+type EventsClient interface {
+	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*Event, error)
+	// publishes all events in one transaction
+	PublishBatch(ctx context.Context, in *PublishBatchRequest, opts ...grpc.CallOption) (*PublishBatchResponse, error)
+	// publishes the streamed events in one transaction once the client closes the stream
+	PublishStream(ctx context.Context, opts ...grpc.CallOption) (Events_PublishStreamClient, error)
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Events_SubscribeClient, error)
+	// at-least-once delivery for consumer groups, events are redelivered until they are acknowledged
+	Consume(ctx context.Context, opts ...grpc.CallOption) (Events_ConsumeClient, error)
+	// streams the stored events of a time window ordered by sequence and ends after the last one
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Events_ExportClient, error)
+	// publishes copies of the events of a time window on the target topic in one transaction, windows of more than 10000 events are rejected
+	Replay(ctx context.Context, in *ReplayRequest, opts ...grpc.CallOption) (*ReplayResponse, error)
 }
 
-type cronJobsClient struct {
-	cc *grpc.ClientConn
+type eventsClient struct {
+	cc grpc.ClientConnInterface
 }
 
-func NewCronJobsClient(cc *grpc.ClientConn) CronJobsClient {
-	return &cronJobsClient{cc}
+func NewEventsClient(cc grpc.ClientConnInterface) EventsClient {
+	return &eventsClient{cc}
 }
 
-func (c *cronJobsClient) Create(ctx context.Context, in *CreateCronJobRequest, opts ...grpc.CallOption) (*CronJob, error) {
-	out := new(CronJob)
-	err := c.cc.Invoke(ctx, "/api.CronJobs/Create", in, out, opts...)
+func (c *eventsClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/api.Events/Publish", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *cronJobsClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*CronJob, error) {
-	out := new(CronJob)
-	err := c.cc.Invoke(ctx, "/api.CronJobs/Get", in, out, opts...)
+func (c *eventsClient) PublishBatch(ctx context.Context, in *PublishBatchRequest, opts ...grpc.CallOption) (*PublishBatchResponse, error) {
+	out := new(PublishBatchResponse)
+	err := c.cc.Invoke(ctx, "/api.Events/PublishBatch", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *cronJobsClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*CronJob, error) {
-	out := new(CronJob)
-	err := c.cc.Invoke(ctx, "/api.CronJobs/Delete", in, out, opts...)
+func (c *eventsClient) PublishStream(ctx context.Context, opts ...grpc.CallOption) (Events_PublishStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Events_serviceDesc.Streams[0], "/api.Events/PublishStream", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &eventsPublishStreamClient{stream}
+	return x, nil
 }
 
-func (c *cronJobsClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (CronJobs_ListClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_CronJobs_serviceDesc.Streams[0], "/api.CronJobs/List", opts...)
+type Events_PublishStreamClient interface {
+	Send(*PublishRequest) error
+	CloseAndRecv() (*PublishBatchResponse, error)
+	grpc.ClientStream
+}
+
+type eventsPublishStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventsPublishStreamClient) Send(m *PublishRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *eventsPublishStreamClient) CloseAndRecv() (*PublishBatchResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(PublishBatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventsClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Events_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Events_serviceDesc.Streams[1], "/api.Events/Subscribe", opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &cronJobsListClient{stream}
+	x := &eventsSubscribeClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -1495,430 +3923,576 @@ func (c *cronJobsClient) List(ctx context.Context, in *ListRequest, opts ...grpc
 	return x, nil
 }
 
-type CronJobs_ListClient interface {
-	Recv() (*CronJob, error)
+type Events_SubscribeClient interface {
+	Recv() (*Event, error)
 	grpc.ClientStream
 }
 
-type cronJobsListClient struct {
+type eventsSubscribeClient struct {
 	grpc.ClientStream
 }
 
-func (x *cronJobsListClient) Recv() (*CronJob, error) {
-	m := new(CronJob)
+func (x *eventsSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-// CronJobsServer is the server API for CronJobs service.
-type CronJobsServer interface {
-	Create(context.Context, *CreateCronJobRequest) (*CronJob, error)
-	Get(context.Context, *GetRequest) (*CronJob, error)
-	Delete(context.Context, *DeleteRequest) (*CronJob, error)
-	List(*ListRequest, CronJobs_ListServer) error
+func (c *eventsClient) Consume(ctx context.Context, opts ...grpc.CallOption) (Events_ConsumeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Events_serviceDesc.Streams[2], "/api.Events/Consume", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventsConsumeClient{stream}
+	return x, nil
 }
 
-// UnimplementedCronJobsServer can be embedded to have forward compatible implementations.
-type UnimplementedCronJobsServer struct {
+type Events_ConsumeClient interface {
+	Send(*ConsumeRequest) error
+	Recv() (*Event, error)
+	grpc.ClientStream
 }
 
-func (*UnimplementedCronJobsServer) Create(ctx context.Context, req *CreateCronJobRequest) (*CronJob, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+type eventsConsumeClient struct {
+	grpc.ClientStream
 }
-func (*UnimplementedCronJobsServer) Get(ctx context.Context, req *GetRequest) (*CronJob, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+
+func (x *eventsConsumeClient) Send(m *ConsumeRequest) error {
+	return x.ClientStream.SendMsg(m)
 }
-func (*UnimplementedCronJobsServer) Delete(ctx context.Context, req *DeleteRequest) (*CronJob, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+
+func (x *eventsConsumeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
-func (*UnimplementedCronJobsServer) List(req *ListRequest, srv CronJobs_ListServer) error {
-	return status.Errorf(codes.Unimplemented, "method List not implemented")
+
+func (c *eventsClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Events_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Events_serviceDesc.Streams[3], "/api.Events/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventsExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func RegisterCronJobsServer(s *grpc.Server, srv CronJobsServer) {
-	s.RegisterService(&_CronJobs_serviceDesc, srv)
+type Events_ExportClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
 }
 
-func _CronJobs_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateCronJobRequest)
+type eventsExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventsExportClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventsClient) Replay(ctx context.Context, in *ReplayRequest, opts ...grpc.CallOption) (*ReplayResponse, error) {
+	out := new(ReplayResponse)
+	err := c.cc.Invoke(ctx, "/api.Events/Replay", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EventsServer is the server API for Events service.
+type EventsServer interface {
+	Publish(context.Context, *PublishRequest) (*Event, error)
+	// publishes all events in one transaction
+	PublishBatch(context.Context, *PublishBatchRequest) (*PublishBatchResponse, error)
+	// publishes the streamed events in one transaction once the client closes the stream
+	PublishStream(Events_PublishStreamServer) error
+	Subscribe(*SubscribeRequest, Events_SubscribeServer) error
+	// at-least-once delivery for consumer groups, events are redelivered until they are acknowledged
+	Consume(Events_ConsumeServer) error
+	// streams the stored events of a time window ordered by sequence and ends after the last one
+	Export(*ExportRequest, Events_ExportServer) error
+	// publishes copies of the events of a time window on the target topic in one transaction, windows of more than 10000 events are rejected
+	Replay(context.Context, *ReplayRequest) (*ReplayResponse, error)
+}
+
+// UnimplementedEventsServer can be embedded to have forward compatible implementations.
+type UnimplementedEventsServer struct {
+}
+
+func (*UnimplementedEventsServer) Publish(ctx context.Context, req *PublishRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Publish not implemented")
+}
+func (*UnimplementedEventsServer) PublishBatch(ctx context.Context, req *PublishBatchRequest) (*PublishBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishBatch not implemented")
+}
+func (*UnimplementedEventsServer) PublishStream(srv Events_PublishStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method PublishStream not implemented")
+}
+func (*UnimplementedEventsServer) Subscribe(req *SubscribeRequest, srv Events_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (*UnimplementedEventsServer) Consume(srv Events_ConsumeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Consume not implemented")
+}
+func (*UnimplementedEventsServer) Export(req *ExportRequest, srv Events_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (*UnimplementedEventsServer) Replay(ctx context.Context, req *ReplayRequest) (*ReplayResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Replay not implemented")
+}
+
+func RegisterEventsServer(s *grpc.Server, srv EventsServer) {
+	s.RegisterService(&_Events_serviceDesc, srv)
+}
+
+func _Events_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CronJobsServer).Create(ctx, in)
+		return srv.(EventsServer).Publish(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.CronJobs/Create",
+		FullMethod: "/api.Events/Publish",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CronJobsServer).Create(ctx, req.(*CreateCronJobRequest))
+		return srv.(EventsServer).Publish(ctx, req.(*PublishRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CronJobs_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetRequest)
+func _Events_PublishBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishBatchRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CronJobsServer).Get(ctx, in)
+		return srv.(EventsServer).PublishBatch(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.CronJobs/Get",
+		FullMethod: "/api.Events/PublishBatch",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CronJobsServer).Get(ctx, req.(*GetRequest))
+		return srv.(EventsServer).PublishBatch(ctx, req.(*PublishBatchRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CronJobs_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteRequest)
-	if err := dec(in); err != nil {
+func _Events_PublishStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EventsServer).PublishStream(&eventsPublishStreamServer{stream})
+}
+
+type Events_PublishStreamServer interface {
+	SendAndClose(*PublishBatchResponse) error
+	Recv() (*PublishRequest, error)
+	grpc.ServerStream
+}
+
+type eventsPublishStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventsPublishStreamServer) SendAndClose(m *PublishBatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *eventsPublishStreamServer) Recv() (*PublishRequest, error) {
+	m := new(PublishRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Events_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventsServer).Subscribe(m, &eventsSubscribeServer{stream})
+}
+
+type Events_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type eventsSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventsSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Events_Consume_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EventsServer).Consume(&eventsConsumeServer{stream})
+}
+
+type Events_ConsumeServer interface {
+	Send(*Event) error
+	Recv() (*ConsumeRequest, error)
+	grpc.ServerStream
+}
+
+type eventsConsumeServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventsConsumeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *eventsConsumeServer) Recv() (*ConsumeRequest, error) {
+	m := new(ConsumeRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(CronJobsServer).Delete(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/api.CronJobs/Delete",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CronJobsServer).Delete(ctx, req.(*DeleteRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+	return m, nil
 }
 
-func _CronJobs_List_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(ListRequest)
+func _Events_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(CronJobsServer).List(m, &cronJobsListServer{stream})
+	return srv.(EventsServer).Export(m, &eventsExportServer{stream})
 }
 
-type CronJobs_ListServer interface {
-	Send(*CronJob) error
+type Events_ExportServer interface {
+	Send(*Event) error
 	grpc.ServerStream
 }
 
-type cronJobsListServer struct {
+type eventsExportServer struct {
 	grpc.ServerStream
 }
 
-func (x *cronJobsListServer) Send(m *CronJob) error {
+func (x *eventsExportServer) Send(m *Event) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-var _CronJobs_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "api.CronJobs",
-	HandlerType: (*CronJobsServer)(nil),
+func _Events_Replay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).Replay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Events/Replay",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).Replay(ctx, req.(*ReplayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Events_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Events",
+	HandlerType: (*EventsServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Create",
-			Handler:    _CronJobs_Create_Handler,
+			MethodName: "Publish",
+			Handler:    _Events_Publish_Handler,
 		},
 		{
-			MethodName: "Get",
-			Handler:    _CronJobs_Get_Handler,
+			MethodName: "PublishBatch",
+			Handler:    _Events_PublishBatch_Handler,
 		},
 		{
-			MethodName: "Delete",
-			Handler:    _CronJobs_Delete_Handler,
+			MethodName: "Replay",
+			Handler:    _Events_Replay_Handler,
 		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "List",
-			Handler:       _CronJobs_List_Handler,
+			StreamName:    "PublishStream",
+			Handler:       _Events_PublishStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Events_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Consume",
+			Handler:       _Events_Consume_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _Events_Export_Handler,
 			ServerStreams: true,
 		},
 	},
 	Metadata: "core.proto",
 }
 
-// LocksClient is the client API for Locks service.
+// SchemasClient is the client API for Schemas service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type LocksClient interface {
-	Aquire(ctx context.Context, in *AquireRequest, opts ...grpc.CallOption) (*AquireResponse, error)
-	Hold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*HoldResponse, error)
-	Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error)
+type SchemasClient interface {
+	Register(ctx context.Context, in *RegisterSchemaRequest, opts ...grpc.CallOption) (*EventSchema, error)
+	Get(ctx context.Context, in *GetSchemaRequest, opts ...grpc.CallOption) (*EventSchema, error)
+	// lists all versions ordered by topic and version
+	List(ctx context.Context, in *ListSchemasRequest, opts ...grpc.CallOption) (Schemas_ListClient, error)
 }
 
-type locksClient struct {
-	cc *grpc.ClientConn
+type schemasClient struct {
+	cc grpc.ClientConnInterface
 }
 
-func NewLocksClient(cc *grpc.ClientConn) LocksClient {
-	return &locksClient{cc}
+func NewSchemasClient(cc grpc.ClientConnInterface) SchemasClient {
+	return &schemasClient{cc}
 }
 
-func (c *locksClient) Aquire(ctx context.Context, in *AquireRequest, opts ...grpc.CallOption) (*AquireResponse, error) {
-	out := new(AquireResponse)
-	err := c.cc.Invoke(ctx, "/api.Locks/Aquire", in, out, opts...)
+func (c *schemasClient) Register(ctx context.Context, in *RegisterSchemaRequest, opts ...grpc.CallOption) (*EventSchema, error) {
+	out := new(EventSchema)
+	err := c.cc.Invoke(ctx, "/api.Schemas/Register", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *locksClient) Hold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*HoldResponse, error) {
-	out := new(HoldResponse)
-	err := c.cc.Invoke(ctx, "/api.Locks/Hold", in, out, opts...)
+func (c *schemasClient) Get(ctx context.Context, in *GetSchemaRequest, opts ...grpc.CallOption) (*EventSchema, error) {
+	out := new(EventSchema)
+	err := c.cc.Invoke(ctx, "/api.Schemas/Get", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *locksClient) Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error) {
-	out := new(ReleaseResponse)
-	err := c.cc.Invoke(ctx, "/api.Locks/Release", in, out, opts...)
+func (c *schemasClient) List(ctx context.Context, in *ListSchemasRequest, opts ...grpc.CallOption) (Schemas_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Schemas_serviceDesc.Streams[0], "/api.Schemas/List", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &schemasListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-// LocksServer is the server API for Locks service.
-type LocksServer interface {
-	Aquire(context.Context, *AquireRequest) (*AquireResponse, error)
-	Hold(context.Context, *HoldRequest) (*HoldResponse, error)
-	Release(context.Context, *ReleaseRequest) (*ReleaseResponse, error)
+type Schemas_ListClient interface {
+	Recv() (*EventSchema, error)
+	grpc.ClientStream
 }
 
-// UnimplementedLocksServer can be embedded to have forward compatible implementations.
-type UnimplementedLocksServer struct {
+type schemasListClient struct {
+	grpc.ClientStream
 }
 
-func (*UnimplementedLocksServer) Aquire(ctx context.Context, req *AquireRequest) (*AquireResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Aquire not implemented")
+func (x *schemasListClient) Recv() (*EventSchema, error) {
+	m := new(EventSchema)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
-func (*UnimplementedLocksServer) Hold(ctx context.Context, req *HoldRequest) (*HoldResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Hold not implemented")
+
+// SchemasServer is the server API for Schemas service.
+type SchemasServer interface {
+	Register(context.Context, *RegisterSchemaRequest) (*EventSchema, error)
+	Get(context.Context, *GetSchemaRequest) (*EventSchema, error)
+	// lists all versions ordered by topic and version
+	List(*ListSchemasRequest, Schemas_ListServer) error
 }
-func (*UnimplementedLocksServer) Release(ctx context.Context, req *ReleaseRequest) (*ReleaseResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Release not implemented")
+
+// UnimplementedSchemasServer can be embedded to have forward compatible implementations.
+type UnimplementedSchemasServer struct {
 }
 
-func RegisterLocksServer(s *grpc.Server, srv LocksServer) {
-	s.RegisterService(&_Locks_serviceDesc, srv)
+func (*UnimplementedSchemasServer) Register(ctx context.Context, req *RegisterSchemaRequest) (*EventSchema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (*UnimplementedSchemasServer) Get(ctx context.Context, req *GetSchemaRequest) (*EventSchema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (*UnimplementedSchemasServer) List(req *ListSchemasRequest, srv Schemas_ListServer) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
 }
 
-func _Locks_Aquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AquireRequest)
+func RegisterSchemasServer(s *grpc.Server, srv SchemasServer) {
+	s.RegisterService(&_Schemas_serviceDesc, srv)
+}
+
+func _Schemas_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterSchemaRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(LocksServer).Aquire(ctx, in)
+		return srv.(SchemasServer).Register(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Locks/Aquire",
+		FullMethod: "/api.Schemas/Register",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LocksServer).Aquire(ctx, req.(*AquireRequest))
+		return srv.(SchemasServer).Register(ctx, req.(*RegisterSchemaRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Locks_Hold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HoldRequest)
+func _Schemas_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSchemaRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(LocksServer).Hold(ctx, in)
+		return srv.(SchemasServer).Get(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Locks/Hold",
+		FullMethod: "/api.Schemas/Get",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LocksServer).Hold(ctx, req.(*HoldRequest))
+		return srv.(SchemasServer).Get(ctx, req.(*GetSchemaRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Locks_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReleaseRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(LocksServer).Release(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/api.Locks/Release",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LocksServer).Release(ctx, req.(*ReleaseRequest))
+func _Schemas_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListSchemasRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return interceptor(ctx, in, info, handler)
+	return srv.(SchemasServer).List(m, &schemasListServer{stream})
 }
 
-var _Locks_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "api.Locks",
-	HandlerType: (*LocksServer)(nil),
+type Schemas_ListServer interface {
+	Send(*EventSchema) error
+	grpc.ServerStream
+}
+
+type schemasListServer struct {
+	grpc.ServerStream
+}
+
+func (x *schemasListServer) Send(m *EventSchema) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Schemas_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Schemas",
+	HandlerType: (*SchemasServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Aquire",
-			Handler:    _Locks_Aquire_Handler,
+			MethodName: "Register",
+			Handler:    _Schemas_Register_Handler,
 		},
 		{
-			MethodName: "Hold",
-			Handler:    _Locks_Hold_Handler,
+			MethodName: "Get",
+			Handler:    _Schemas_Get_Handler,
 		},
+	},
+	Streams: []grpc.StreamDesc{
 		{
-			MethodName: "Release",
-			Handler:    _Locks_Release_Handler,
+			StreamName:    "List",
+			Handler:       _Schemas_List_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "core.proto",
 }
 
-// EventsClient is the client API for Events service.
+// BackboneClient is the client API for Backbone service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type EventsClient interface {
-	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*Event, error)
-	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Events_SubscribeClient, error)
+type BackboneClient interface {
+	// executes all operations in one transaction, nothing is applied if one of them fails
+	Transact(ctx context.Context, in *TransactRequest, opts ...grpc.CallOption) (*TransactResponse, error)
 }
 
-type eventsClient struct {
-	cc *grpc.ClientConn
+type backboneClient struct {
+	cc grpc.ClientConnInterface
 }
 
-func NewEventsClient(cc *grpc.ClientConn) EventsClient {
-	return &eventsClient{cc}
+func NewBackboneClient(cc grpc.ClientConnInterface) BackboneClient {
+	return &backboneClient{cc}
 }
 
-func (c *eventsClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*Event, error) {
-	out := new(Event)
-	err := c.cc.Invoke(ctx, "/api.Events/Publish", in, out, opts...)
+func (c *backboneClient) Transact(ctx context.Context, in *TransactRequest, opts ...grpc.CallOption) (*TransactResponse, error) {
+	out := new(TransactResponse)
+	err := c.cc.Invoke(ctx, "/api.Backbone/Transact", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *eventsClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Events_SubscribeClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Events_serviceDesc.Streams[0], "/api.Events/Subscribe", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &eventsSubscribeClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
-}
-
-type Events_SubscribeClient interface {
-	Recv() (*Event, error)
-	grpc.ClientStream
-}
-
-type eventsSubscribeClient struct {
-	grpc.ClientStream
-}
-
-func (x *eventsSubscribeClient) Recv() (*Event, error) {
-	m := new(Event)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
-}
-
-// EventsServer is the server API for Events service.
-type EventsServer interface {
-	Publish(context.Context, *PublishRequest) (*Event, error)
-	Subscribe(*SubscribeRequest, Events_SubscribeServer) error
+// BackboneServer is the server API for Backbone service.
+type BackboneServer interface {
+	// executes all operations in one transaction, nothing is applied if one of them fails
+	Transact(context.Context, *TransactRequest) (*TransactResponse, error)
 }
 
-// UnimplementedEventsServer can be embedded to have forward compatible implementations.
-type UnimplementedEventsServer struct {
+// UnimplementedBackboneServer can be embedded to have forward compatible implementations.
+type UnimplementedBackboneServer struct {
 }
 
-func (*UnimplementedEventsServer) Publish(ctx context.Context, req *PublishRequest) (*Event, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Publish not implemented")
-}
-func (*UnimplementedEventsServer) Subscribe(req *SubscribeRequest, srv Events_SubscribeServer) error {
-	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+func (*UnimplementedBackboneServer) Transact(ctx context.Context, req *TransactRequest) (*TransactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transact not implemented")
 }
 
-func RegisterEventsServer(s *grpc.Server, srv EventsServer) {
-	s.RegisterService(&_Events_serviceDesc, srv)
+func RegisterBackboneServer(s *grpc.Server, srv BackboneServer) {
+	s.RegisterService(&_Backbone_serviceDesc, srv)
 }
 
-func _Events_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PublishRequest)
+func _Backbone_Transact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(EventsServer).Publish(ctx, in)
+		return srv.(BackboneServer).Transact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/api.Events/Publish",
+		FullMethod: "/api.Backbone/Transact",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EventsServer).Publish(ctx, req.(*PublishRequest))
+		return srv.(BackboneServer).Transact(ctx, req.(*TransactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Events_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(SubscribeRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(EventsServer).Subscribe(m, &eventsSubscribeServer{stream})
-}
-
-type Events_SubscribeServer interface {
-	Send(*Event) error
-	grpc.ServerStream
-}
-
-type eventsSubscribeServer struct {
-	grpc.ServerStream
-}
-
-func (x *eventsSubscribeServer) Send(m *Event) error {
-	return x.ServerStream.SendMsg(m)
-}
-
-var _Events_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "api.Events",
-	HandlerType: (*EventsServer)(nil),
+var _Backbone_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Backbone",
+	HandlerType: (*BackboneServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Publish",
-			Handler:    _Events_Publish_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "Subscribe",
-			Handler:       _Events_Subscribe_Handler,
-			ServerStreams: true,
+			MethodName: "Transact",
+			Handler:    _Backbone_Transact_Handler,
 		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "core.proto",
 }
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// apiPrefix is the prefix of all backbone-tools methods, other methods (e.g. reflection) are not authenticated
+const apiPrefix = "/api."
+
+type contextKey struct{}
+
+type authContext struct {
+	principal string
+	config    *Config
+}
+
+// WithAuth authenticates all api requests and enables authorization checks in the services.
+// It implements the option interface of github.com/contiamo/goserver/grpc.
+func WithAuth(cfg *Config) *Option {
+	return &Option{cfg}
+}
+
+// Option is a goserver option enabling authentication
+type Option struct {
+	cfg *Config
+}
+
+// GetOptions returns the interceptors performing the authentication
+func (opt *Option) GetOptions() (grpc.ServerOption, grpc.StreamServerInterceptor, grpc.UnaryServerInterceptor, error) {
+	ui := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := opt.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	si := func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := opt.authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{stream, ctx})
+	}
+	return nil, si, ui, nil
+}
+
+// PostProcess is a noop
+func (opt *Option) PostProcess(s *grpc.Server) error {
+	return nil
+}
+
+func (opt *Option) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if !strings.HasPrefix(method, apiPrefix) {
+		return ctx, nil
+	}
+	principal, ok := opt.principalFromToken(ctx)
+	if !ok {
+		principal, ok = opt.principalFromCertificate(ctx)
+	}
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "no valid bearer token or client certificate")
+	}
+	return context.WithValue(ctx, contextKey{}, &authContext{principal, opt.cfg}), nil
+}
+
+func (opt *Option) principalFromToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, header := range md.Get("authorization") {
+		if !strings.HasPrefix(header, "Bearer ") {
+			continue
+		}
+		token := strings.TrimPrefix(header, "Bearer ")
+		for candidate, principal := range opt.cfg.Tokens {
+			if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+				return principal, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (opt *Option) principalFromCertificate(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", false
+	}
+	for _, chain := range tlsInfo.State.VerifiedChains {
+		if len(chain) == 0 {
+			continue
+		}
+		subject := chain[0].Subject
+		if principal, ok := opt.cfg.Subjects[subject.String()]; ok {
+			return principal, true
+		}
+		if principal, ok := opt.cfg.Subjects[subject.CommonName]; ok {
+			return principal, true
+		}
+	}
+	return "", false
+}
+
+// Principal returns the authenticated principal of a request
+func Principal(ctx context.Context) (string, bool) {
+	a, ok := ctx.Value(contextKey{}).(*authContext)
+	if !ok || a == nil {
+		return "", false
+	}
+	return a.principal, true
+}
+
+// Authorize checks that the principal of the request may perform the action on the resource in the namespace.
+// If authentication is disabled or the context is a system context every action is allowed.
+func Authorize(ctx context.Context, ns, action, resource string) error {
+	a, ok := ctx.Value(contextKey{}).(*authContext)
+	if !ok || a == nil {
+		return nil
+	}
+	if !a.config.Allowed(a.principal, ns, action, resource) {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to %s %s in namespace %s", a.principal, action, resource, ns)
+	}
+	return nil
+}
+
+// NewSystemContext returns a context which is not subject to authorization checks.
+// It is used for server internal calls, e.g. when the cronjob scheduler creates jobs.
+func NewSystemContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, (*authContext)(nil))
+}
+
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Config describes how clients are authenticated and what they are allowed to do.
+//
+// Example:
+//
+//	tokens:
+//	  s3cr3t: reporting-service
+//	subjects:
+//	  worker.example.com: worker
+//	policies:
+//	  worker:
+//	    - actions: ["jobs:listen", "jobs:heartbeat"]
+//	      resources: ["exports", "reports-*"]
+//	    - actions: ["locks:*"]
+//	      resources: ["worker/*"]
+//	      namespaces: ["team-a", "team-b"]
+//	  "*":
+//	    - actions: ["events:subscribe"]
+//	      resources: ["public.*"]
+type Config struct {
+	// Tokens maps bearer tokens to principals
+	Tokens map[string]string `yaml:"tokens"`
+	// Subjects maps client certificate subjects (or their common names) to principals
+	Subjects map[string]string `yaml:"subjects"`
+	// Policies maps principals to their grants, grants of "*" apply to every authenticated principal
+	Policies map[string][]Grant `yaml:"policies"`
+}
+
+// Grant allows a set of actions on a set of resources in a set of namespaces.
+// Actions have the form <service>:<method>, resources are queues, topics or lock ids.
+// All of them support a trailing "*" to match by prefix.
+// A grant without namespaces only applies to the default namespace.
+type Grant struct {
+	Actions    []string `yaml:"actions"`
+	Resources  []string `yaml:"resources"`
+	Namespaces []string `yaml:"namespaces"`
+}
+
+// LoadConfig reads an auth config from a yaml file
+func LoadConfig(path string) (*Config, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(bs, cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse auth config %s", path)
+	}
+	return cfg, nil
+}
+
+// Allowed checks whether the principal may perform the action on the resource in the namespace
+func (cfg *Config) Allowed(principal, ns, action, resource string) bool {
+	for _, p := range []string{principal, "*"} {
+		for _, grant := range cfg.Policies[p] {
+			if matchAny(grant.namespaces(), ns) && matchAny(grant.Actions, action) && matchAny(grant.Resources, resource) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (grant Grant) namespaces() []string {
+	if len(grant.Namespaces) == 0 {
+		return []string{namespace.Default}
+	}
+	return grant.Namespaces
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if match(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pattern, value string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(value, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == value
+}
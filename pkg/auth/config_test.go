@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestAllowed(t *testing.T) {
+	cfg := &Config{}
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+policies:
+  worker:
+    - actions: ["jobs:listen", "jobs:heartbeat"]
+      resources: ["exports", "reports-*"]
+    - actions: ["locks:*"]
+      resources: ["worker/*"]
+  "*":
+    - actions: ["events:subscribe"]
+      resources: ["public.*"]
+`), cfg))
+
+	require.True(t, cfg.Allowed("worker", "default", "jobs:listen", "exports"))
+	require.True(t, cfg.Allowed("worker", "default", "jobs:heartbeat", "reports-daily"))
+	require.False(t, cfg.Allowed("worker", "default", "jobs:create", "exports"))
+	require.False(t, cfg.Allowed("worker", "default", "jobs:listen", "exports-2"))
+	require.True(t, cfg.Allowed("worker", "default", "locks:release", "worker/1"))
+	require.False(t, cfg.Allowed("worker", "default", "locks:release", "other/1"))
+	require.True(t, cfg.Allowed("someone", "default", "events:subscribe", "public.news"))
+	require.False(t, cfg.Allowed("someone", "default", "jobs:listen", "exports"))
+	require.False(t, cfg.Allowed("worker", "default", "jobs:list", "*"))
+	require.False(t, cfg.Allowed("worker", "team-a", "jobs:listen", "exports"))
+}
+
+func TestAuthorizeNamespaces(t *testing.T) {
+	cfg := &Config{}
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+policies:
+  billing-service:
+    - actions: ["jobs:*"]
+      resources: ["billing"]
+      namespaces: ["team-a"]
+  auditor:
+    - actions: ["jobs:list"]
+      resources: ["*"]
+      namespaces: ["*"]
+`), cfg))
+
+	billing := context.WithValue(context.Background(), contextKey{}, &authContext{"billing-service", cfg})
+	require.NoError(t, Authorize(billing, "team-a", "jobs:create", "billing"))
+	require.NoError(t, Authorize(billing, "team-a", "jobs:delete", "billing"))
+	for _, ns := range []string{"team-b", "default"} {
+		err := Authorize(billing, ns, "jobs:create", "billing")
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+
+	auditor := context.WithValue(context.Background(), contextKey{}, &authContext{"auditor", cfg})
+	require.NoError(t, Authorize(auditor, "team-b", "jobs:list", "*"))
+	require.Equal(t, codes.PermissionDenied, status.Code(Authorize(auditor, "team-b", "jobs:delete", "billing")))
+
+	require.NoError(t, Authorize(NewSystemContext(billing), "team-b", "jobs:delete", "billing"))
+	require.NoError(t, Authorize(context.Background(), "team-b", "jobs:delete", "billing"))
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// BearerToken returns per RPC credentials sending the token in the authorization header.
+// Set allowInsecure to also send it over connections without transport security.
+func BearerToken(token string, allowInsecure bool) credentials.PerRPCCredentials {
+	return &bearerToken{token, allowInsecure}
+}
+
+type bearerToken struct {
+	token         string
+	allowInsecure bool
+}
+
+func (t *bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t *bearerToken) RequireTransportSecurity() bool {
+	return !t.allowInsecure
+}
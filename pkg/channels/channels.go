@@ -0,0 +1,198 @@
+package channels
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// MaxNameLength is the maximum length of queue and topic names
+	MaxNameLength = 200
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._:/-]*$`)
+
+// Execer is the subset of *sql.DB and *sql.Tx needed to send notifications
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// ValidateName checks that a queue or topic name only contains safe characters and is not too long
+func ValidateName(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s name must not be empty", kind)
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("%s name must not be longer than %d bytes", kind, MaxNameLength)
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%s name %q contains invalid characters (allowed are letters, digits and . _ : / -)", kind, name)
+	}
+	return nil
+}
+
+// ValidateTopicPattern checks a topic or a pattern matching a family of topics.
+// Topics are dot separated tokens, in patterns "*" matches exactly one token and ">" as the last token matches one or more tokens.
+func ValidateTopicPattern(pattern string) error {
+	if !IsPattern(pattern) {
+		return ValidateName("topic", pattern)
+	}
+	tokens := strings.Split(pattern, ".")
+	for i, token := range tokens {
+		switch {
+		case token == "":
+			return fmt.Errorf("topic pattern %q contains empty tokens", pattern)
+		case token == ">" && i != len(tokens)-1:
+			return fmt.Errorf("topic pattern %q may only contain '>' as the last token", pattern)
+		case token == "*" || token == ">":
+			tokens[i] = "x"
+		case strings.ContainsAny(token, "*>"):
+			return fmt.Errorf("topic pattern %q may only contain wildcards as whole tokens", pattern)
+		}
+	}
+	return ValidateName("topic", strings.Join(tokens, "."))
+}
+
+// IsPattern returns true if the topic contains wildcards
+func IsPattern(topic string) bool {
+	return strings.ContainsAny(topic, "*>")
+}
+
+// TopicRegexp returns a regular expression matching the topics of a valid pattern.
+// It works for both postgres and go.
+func TopicRegexp(pattern string) string {
+	tokens := strings.Split(pattern, ".")
+	for i, token := range tokens {
+		switch token {
+		case "*":
+			tokens[i] = `[^.]+`
+		case ">":
+			tokens[i] = `.+`
+		default:
+			tokens[i] = regexp.QuoteMeta(token)
+		}
+	}
+	return "^" + strings.Join(tokens, `\.`) + "$"
+}
+
+// MatchTopic returns true if a topic matches a valid topic or pattern
+func MatchTopic(pattern, topic string) bool {
+	if !IsPattern(pattern) {
+		return pattern == topic
+	}
+	return compiledPattern(pattern).MatchString(topic)
+}
+
+// maxCachedPatterns bounds the number of compiled topic patterns kept in memory
+const maxCachedPatterns = 1024
+
+var compiledPatterns = struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+func compiledPattern(pattern string) *regexp.Regexp {
+	compiledPatterns.Lock()
+	defer compiledPatterns.Unlock()
+	if re, ok := compiledPatterns.m[pattern]; ok {
+		return re
+	}
+	if len(compiledPatterns.m) >= maxCachedPatterns {
+		compiledPatterns.m = make(map[string]*regexp.Regexp)
+	}
+	re := regexp.MustCompile(TopicRegexp(pattern))
+	compiledPatterns.m[pattern] = re
+	return re
+}
+
+// Jobs returns the notification channel for a job queue
+func Jobs(schema, namespace, queue string) string {
+	return hashed("jobs", schema, namespace, queue)
+}
+
+// Events returns the notification channel for an event topic
+func Events(schema, namespace, topic string) string {
+	return hashed("events", schema, namespace, topic)
+}
+
+// EventsPublished returns the notification channels to notify when events are published on the given topics:
+// the channels of the topics themselves and the channels of all their prefixes, which wake pattern subscribers.
+// Every channel is returned once, so a transaction publishing on many topics sends each notification once.
+func EventsPublished(schema, namespace string, topics ...string) []string {
+	var (
+		res  []string
+		seen = make(map[string]bool)
+	)
+	add := func(channel string) {
+		if !seen[channel] {
+			seen[channel] = true
+			res = append(res, channel)
+		}
+	}
+	for _, topic := range topics {
+		add(Events(schema, namespace, topic))
+		add(eventsPrefix(schema, namespace, ""))
+		for i, c := range topic {
+			if c == '.' {
+				add(eventsPrefix(schema, namespace, topic[:i]))
+			}
+		}
+	}
+	return res
+}
+
+// EventsSubscription returns the notification channel to listen on for a topic or pattern.
+// Patterns listen on the channel of the tokens before their first wildcard.
+func EventsSubscription(schema, namespace, pattern string) string {
+	if !IsPattern(pattern) {
+		return Events(schema, namespace, pattern)
+	}
+	var literal []string
+	for _, token := range strings.Split(pattern, ".") {
+		if token == "*" || token == ">" {
+			break
+		}
+		literal = append(literal, token)
+	}
+	return eventsPrefix(schema, namespace, strings.Join(literal, "."))
+}
+
+func eventsPrefix(schema, namespace, prefix string) string {
+	return hashed("eventprefix", schema, namespace, prefix)
+}
+
+// LockWaiter returns the notification channel used to wake a single waiter of a lock
+func LockWaiter(schema, waiterID string) string {
+	return hashed("lockwaiter", schema, waiterID)
+}
+
+// Semaphore returns the notification channel used to signal freed permits of a semaphore
+func Semaphore(schema, namespace, name string) string {
+	return hashed("semaphore", schema, namespace, name)
+}
+
+// Quote quotes a channel name so it can be used in LISTEN / UNLISTEN statements
+func Quote(channel string) string {
+	return pgx.Identifier{channel}.Sanitize()
+}
+
+// Notify sends a notification on the given channel
+func Notify(ctx context.Context, db Execer, channel string) error {
+	_, err := db.ExecContext(ctx, `SELECT pg_notify($1, '')`, channel)
+	return err
+}
+
+// hashed maps arbitrary names to lowercase identifiers well below the 63 byte limit of postgres.
+// Schema and namespace are part of the hash so isolated tenants sharing a database do not wake each other.
+func hashed(prefix string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return prefix + "_" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,82 @@
+package channels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"q1", "orders.created", "team-a/jobs", "Upper_Case:1"} {
+		require.NoError(t, ValidateName("queue", name), name)
+	}
+	for _, name := range []string{"", " q", "q 1", "q;NOTIFY x", ".q", "q\"", strings.Repeat("a", MaxNameLength+1)} {
+		require.Error(t, ValidateName("queue", name), name)
+	}
+}
+
+func TestChannelNames(t *testing.T) {
+	long := strings.Repeat("a", MaxNameLength)
+	for _, name := range []string{"q1", "Q1", "orders.created", long} {
+		ch := Jobs("", "default", name)
+		require.True(t, len(ch) <= 63, ch)
+		require.Equal(t, strings.ToLower(ch), ch)
+		require.Equal(t, ch, Jobs("", "default", name))
+	}
+	require.NotEqual(t, Jobs("", "default", "q1"), Jobs("", "default", "Q1"))
+	require.NotEqual(t, Jobs("", "default", "a-b"), Jobs("", "default", "a_b"))
+	require.NotEqual(t, Jobs("", "default", "q1"), Events("", "default", "q1"))
+	require.NotEqual(t, Jobs("", "default", "q1"), Jobs("", "tenant", "q1"))
+	require.NotEqual(t, LockWaiter("", "w1"), LockWaiter("tenant", "w1"))
+	require.NotEqual(t, LockWaiter("", "w1"), LockWaiter("", "w2"))
+	require.NotEqual(t, Semaphore("", "ns", "s1"), Semaphore("tenant", "ns", "s1"))
+	require.NotEqual(t, Semaphore("", "ns", "s1"), Semaphore("", "other", "s1"))
+	require.NotEqual(t, Semaphore("", "ns", "s1"), Semaphore("", "ns", "s2"))
+	require.Equal(t, `"`+Semaphore("", "ns", "s1")+`"`, Quote(Semaphore("", "ns", "s1")))
+}
+
+func TestTopicPatterns(t *testing.T) {
+	for _, pattern := range []string{"orders", "orders.*", "orders.>", "*.created", "orders.*.eu.>", ">"} {
+		require.NoError(t, ValidateTopicPattern(pattern), pattern)
+	}
+	for _, pattern := range []string{"", "orders.>.created", "orders*", "orders.**", "orders..*", ".*", "orders.*;x"} {
+		require.Error(t, ValidateTopicPattern(pattern), pattern)
+	}
+
+	matches := MatchTopic
+	require.True(t, matches("orders.*", "orders.created"))
+	require.False(t, matches("orders.*", "orders"))
+	require.False(t, matches("orders.*", "orders.eu.created"))
+	require.False(t, matches("orders.*", "ordersxcreated"))
+	require.True(t, matches("orders.>", "orders.created"))
+	require.True(t, matches("orders.>", "orders.eu.created"))
+	require.False(t, matches("orders.>", "orders"))
+	require.True(t, matches("*.created", "orders.created"))
+	require.False(t, matches("*.created", "orders.eu.created"))
+	require.True(t, matches("orders.*.created", "orders.eu.created"))
+	require.False(t, matches("orders.*.created", "orders.eu.deleted"))
+	require.True(t, matches("orders", "orders"))
+	require.False(t, matches("orders", "orders.created"))
+}
+
+func TestEventChannels(t *testing.T) {
+	published := EventsPublished("", "default", "orders.eu.created")
+	require.Contains(t, published, Events("", "default", "orders.eu.created"))
+	for _, pattern := range []string{"orders.eu.created", "orders.>", "orders.*.created", "orders.eu.*", "*.eu.created", ">"} {
+		require.Contains(t, published, EventsSubscription("", "default", pattern), pattern)
+	}
+	require.NotContains(t, published, EventsSubscription("", "default", "invoices.>"))
+	require.NotContains(t, published, EventsSubscription("", "tenant", "orders.>"))
+
+	// topics share the notifications of their common prefixes
+	published = EventsPublished("", "default", "orders.eu.created", "orders.eu.deleted", "orders.us.created")
+	// the channels of the 3 topics and of the prefixes "", orders, orders.eu and orders.us
+	require.Len(t, published, 3+4)
+	seen := make(map[string]bool)
+	for _, channel := range published {
+		require.False(t, seen[channel], channel)
+		seen[channel] = true
+	}
+	require.Contains(t, published, EventsSubscription("", "default", "orders.us.*"))
+}
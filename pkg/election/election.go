@@ -0,0 +1,175 @@
+// Package election implements leader election on top of the Locks service.
+//
+// Candidates campaign for a named election by acquiring the lock "election/<name>".
+// The leader holds the lock with locks.Lock and loses leadership as soon as a renewal
+// is rejected or the lock expired because renewals failed for longer than its ttl.
+// There is no Election gRPC service, clients in other languages can campaign with the Locks service directly.
+package election
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/locks"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// lockPrefix is prepended to election names to build the lock id
+const lockPrefix = "election/"
+
+var (
+	// ErrAlreadyLeader is returned when campaigning while being the leader
+	ErrAlreadyLeader = errors.New("already leader")
+	// ErrNoLeader is returned by Leader if nobody holds the leadership
+	ErrNoLeader = errors.New("no leader")
+)
+
+// Election is a single candidate in a named election
+type Election struct {
+	cli       api.LocksClient
+	id        string
+	candidate string
+	ttl       time.Duration
+	changes   chan bool
+
+	mu    sync.Mutex
+	lease *locks.Lease
+}
+
+// New creates a candidate for the election with the given name.
+// The candidate name is shown to others asking for the leader, ttl is the time after which the
+// leadership of a crashed leader expires (the server default is used if it is 0).
+func New(cli api.LocksClient, name, candidate string, ttl time.Duration) *Election {
+	return &Election{
+		cli:       cli,
+		id:        lockPrefix + name,
+		candidate: candidate,
+		ttl:       ttl,
+		changes:   make(chan bool, 1),
+	}
+}
+
+// Campaign blocks until the candidate becomes the leader or ctx is done.
+// The returned context is canceled when the leadership is lost or resigned or ctx is done,
+// stop leader-only work when it is done.
+func (e *Election) Campaign(ctx context.Context) (context.Context, error) {
+	if e.IsLeader() {
+		return nil, ErrAlreadyLeader
+	}
+	opts := []locks.Option{locks.WithHolder(e.candidate)}
+	if e.ttl > 0 {
+		opts = append(opts, locks.WithTTL(e.ttl))
+	}
+	lease, err := locks.Lock(ctx, e.cli, e.id, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.lease = lease
+	e.notify(true)
+	e.mu.Unlock()
+	go func() {
+		<-lease.Done()
+		e.lost(lease)
+	}()
+	return lease.Context(), nil
+}
+
+// lost drops the leadership if it still belongs to the given lease
+func (e *Election) lost(lease *locks.Lease) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.lease != lease {
+		return
+	}
+	e.lease = nil
+	e.notify(false)
+}
+
+// Resign gives up the leadership. It is a no-op if the candidate is not the leader.
+func (e *Election) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	lease := e.lease
+	if lease == nil {
+		e.mu.Unlock()
+		return nil
+	}
+	e.lease = nil
+	e.notify(false)
+	e.mu.Unlock()
+
+	err := lease.Release(ctx)
+	if err == locks.ErrLost || status.Code(err) == codes.FailedPrecondition {
+		// the lock expired already
+		return nil
+	}
+	return err
+}
+
+// IsLeader returns true while the candidate holds the leadership
+func (e *Election) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leads()
+}
+
+// Term returns the fencing token of the current leadership or 0 if the candidate is not the leader.
+// Terms grow with every change of leadership.
+func (e *Election) Term() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.leads() {
+		return 0
+	}
+	return e.lease.FencingToken
+}
+
+// leads reports whether the current lease is still held, it must be called with e.mu held.
+// A lost lease is only dropped after its context is done, so the context is checked as well.
+func (e *Election) leads() bool {
+	return e.lease != nil && e.lease.Err() == nil
+}
+
+// Changes returns a channel receiving true when the leadership is gained and false when it is lost or resigned.
+// Only the latest state is buffered, a slow reader may miss intermediate changes.
+func (e *Election) Changes() <-chan bool {
+	return e.changes
+}
+
+// notify replaces a pending change by the latest state, it must be called with e.mu held
+func (e *Election) notify(leader bool) {
+	select {
+	case <-e.changes:
+	default:
+	}
+	e.changes <- leader
+}
+
+// Leader returns the candidate name of the current leader or ErrNoLeader.
+// It needs the locks:list permission for the election lock.
+func (e *Election) Leader(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	resp, err := e.cli.List(ctx, &api.ListLocksRequest{Prefix: e.id})
+	if err != nil {
+		return "", err
+	}
+	for {
+		info, err := resp.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return "", ErrNoLeader
+			}
+			return "", err
+		}
+		if info.GetId() == e.id && info.GetHolders() > 0 {
+			return info.GetHolder(), nil
+		}
+	}
+}
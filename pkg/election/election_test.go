@@ -0,0 +1,125 @@
+package election
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/internal/fakes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCampaignAndResign(t *testing.T) {
+	ctx := context.Background()
+	cli := &fakes.Locks{}
+	a := New(cli, "scheduler", "a", time.Second)
+	b := New(cli, "scheduler", "b", time.Second)
+
+	_, err := a.Leader(ctx)
+	require.Equal(t, ErrNoLeader, err)
+
+	leaderCtx, err := a.Campaign(ctx)
+	require.NoError(t, err)
+	require.True(t, a.IsLeader())
+	require.True(t, <-a.Changes())
+	require.Equal(t, uint64(1), a.Term())
+	leader, err := b.Leader(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "a", leader)
+
+	_, err = a.Campaign(ctx)
+	require.Equal(t, ErrAlreadyLeader, err)
+
+	// b waits until a resigns
+	campaigned := make(chan error, 1)
+	go func() {
+		_, err := b.Campaign(ctx)
+		campaigned <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.False(t, b.IsLeader())
+
+	require.NoError(t, a.Resign(ctx))
+	require.False(t, a.IsLeader())
+	require.False(t, <-a.Changes())
+	require.Error(t, leaderCtx.Err())
+
+	require.NoError(t, <-campaigned)
+	require.True(t, b.IsLeader())
+	require.Equal(t, uint64(2), b.Term())
+}
+
+func TestLostRenewal(t *testing.T) {
+	cli := &fakes.Locks{}
+	e := New(cli, "scheduler", "a", 300*time.Millisecond)
+	leaderCtx, err := e.Campaign(context.Background())
+	require.NoError(t, err)
+	require.True(t, <-e.Changes())
+
+	cli.Break(lockPrefix + "scheduler")
+	select {
+	case <-leaderCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("leadership context was not canceled")
+	}
+	require.False(t, <-e.Changes())
+	require.False(t, e.IsLeader())
+	require.NoError(t, e.Resign(context.Background()))
+}
+
+func TestRenewalErrorsUntilExpiry(t *testing.T) {
+	cli := &fakes.Locks{}
+	cli.SetHoldErr(status.Error(codes.Unavailable, "server down"))
+	e := New(cli, "scheduler", "a", 300*time.Millisecond)
+	start := time.Now()
+	leaderCtx, err := e.Campaign(context.Background())
+	require.NoError(t, err)
+
+	// transient errors are retried, the leadership is only given up once the lease expired
+	select {
+	case <-leaderCtx.Done():
+		require.True(t, time.Since(start) >= 300*time.Millisecond)
+	case <-time.After(2 * time.Second):
+		t.Fatal("leadership context was not canceled")
+	}
+	require.False(t, e.IsLeader())
+}
+
+func TestLeadershipAfterLongCampaign(t *testing.T) {
+	ctx := context.Background()
+	cli := &fakes.Locks{}
+	a := New(cli, "scheduler", "a", 300*time.Millisecond)
+	b := New(cli, "scheduler", "b", 300*time.Millisecond)
+	_, err := a.Campaign(ctx)
+	require.NoError(t, err)
+
+	// b waits longer than the ttl before it becomes the leader
+	go func() {
+		time.Sleep(600 * time.Millisecond)
+		_ = a.Resign(ctx)
+	}()
+	leaderCtx, err := b.Campaign(ctx)
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, leaderCtx.Err())
+	require.True(t, b.IsLeader())
+	require.NoError(t, b.Resign(ctx))
+}
+
+func TestCampaignContext(t *testing.T) {
+	cli := &fakes.Locks{}
+	e := New(cli, "scheduler", "a", time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderCtx, err := e.Campaign(ctx)
+	require.NoError(t, err)
+
+	// the leadership ends with the context of the campaign
+	cancel()
+	<-leaderCtx.Done()
+	require.Eventually(t, func() bool {
+		_, err := e.Leader(context.Background())
+		return !e.IsLeader() && err == ErrNoLeader
+	}, time.Second, 10*time.Millisecond)
+}
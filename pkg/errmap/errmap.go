@@ -0,0 +1,72 @@
+package errmap
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation           = "23505"
+	pgForeignKeyViolation       = "23503"
+	pgNotNullViolation          = "23502"
+	pgCheckViolation            = "23514"
+	pgInvalidTextRepresentation = "22P02"
+	pgSerializationFailure      = "40001"
+	pgDeadlockDetected          = "40P01"
+)
+
+// ToStatus maps errors returned by the services to gRPC status errors.
+// It can be used as error scrubber for grpcserver.WithErrorScrubbing.
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	switch pgCode(err) {
+	case pgUniqueViolation:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case pgForeignKeyViolation:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case pgNotNullViolation, pgCheckViolation, pgInvalidTextRepresentation:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case pgSerializationFailure, pgDeadlockDetected:
+		return status.Error(codes.Aborted, err.Error())
+	}
+	return err
+}
+
+// IsSerializationFailure returns true if the error signals that a transaction
+// could not be serialized and may succeed if retried
+func IsSerializationFailure(err error) bool {
+	code := pgCode(err)
+	return code == pgSerializationFailure || code == pgDeadlockDetected
+}
+
+// IsUniqueViolation returns true if the error was caused by a unique constraint
+func IsUniqueViolation(err error) bool {
+	return pgCode(err) == pgUniqueViolation
+}
+
+func pgCode(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
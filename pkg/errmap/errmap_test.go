@@ -0,0 +1,55 @@
+package errmap
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/robfig/cron"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	_, cronErr := cron.ParseStandard("every now and then")
+	require.Error(t, cronErr)
+	badCron := status.Errorf(codes.InvalidArgument, "invalid cron expression: %v", cronErr)
+	notFound := status.Error(codes.NotFound, "job not found")
+
+	for _, c := range []struct {
+		name string
+		err  error
+		code codes.Code
+		same bool
+	}{
+		{name: "no rows", err: sql.ErrNoRows, code: codes.NotFound},
+		{name: "wrapped no rows", err: pkgerrors.Wrap(sql.ErrNoRows, "failed to get job"), code: codes.NotFound},
+		{name: "unique violation", err: &pq.Error{Code: "23505"}, code: codes.AlreadyExists},
+		{name: "foreign key violation", err: &pq.Error{Code: "23503"}, code: codes.FailedPrecondition},
+		{name: "check violation", err: &pq.Error{Code: "23514"}, code: codes.InvalidArgument},
+		{name: "invalid uuid", err: &pq.Error{Code: "22P02"}, code: codes.InvalidArgument},
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, code: codes.Aborted},
+		{name: "wrapped deadlock", err: pkgerrors.Wrap(&pq.Error{Code: "40P01"}, "failed to lock"), code: codes.Aborted},
+		{name: "canceled", err: context.Canceled, code: codes.Canceled},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, code: codes.DeadlineExceeded},
+		{name: "bad cron expression", err: badCron, code: codes.InvalidArgument, same: true},
+		{name: "status error", err: notFound, code: codes.NotFound, same: true},
+		{name: "other postgres error", err: &pq.Error{Code: "42P01"}, code: codes.Unknown, same: true},
+		{name: "other error", err: errors.New("boom"), code: codes.Unknown, same: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := ToStatus(c.err)
+			require.Equal(t, c.code, status.Code(err))
+			if c.same {
+				require.Equal(t, c.err, err)
+			} else {
+				require.Contains(t, err.Error(), c.err.Error())
+			}
+		})
+	}
+	require.NoError(t, ToStatus(nil))
+}
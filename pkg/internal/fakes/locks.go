@@ -0,0 +1,142 @@
+// Package fakes provides in-memory fakes of the gRPC clients and server streams for tests.
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Locks is a LocksClient keeping exclusive locks in memory. Acquiring a held lock waits until it is released.
+type Locks struct {
+	api.LocksClient
+
+	// AquireDelay simulates waiting for the lock before every acquisition
+	AquireDelay time.Duration
+
+	mu       sync.Mutex
+	holders  map[string]*lockHolder
+	fencing  uint64
+	holdErr  error
+	releases int
+}
+
+type lockHolder struct {
+	name    string
+	token   string
+	fencing uint64
+}
+
+func (f *Locks) Aquire(ctx context.Context, req *api.AquireRequest, opts ...grpc.CallOption) (*api.AquireResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(f.AquireDelay):
+	}
+	for {
+		f.mu.Lock()
+		if f.holders == nil {
+			f.holders = make(map[string]*lockHolder)
+		}
+		if _, ok := f.holders[req.GetId()]; !ok {
+			f.fencing++
+			holder := &lockHolder{name: req.GetHolder(), token: fmt.Sprintf("token-%d", f.fencing), fencing: f.fencing}
+			f.holders[req.GetId()] = holder
+			f.mu.Unlock()
+			return &api.AquireResponse{
+				Id:           req.GetId(),
+				Token:        holder.token,
+				FencingToken: holder.fencing,
+				Ttl:          req.GetTtl(),
+			}, nil
+		}
+		f.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (f *Locks) Hold(ctx context.Context, req *api.HoldRequest, opts ...grpc.CallOption) (*api.HoldResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.holdErr != nil {
+		return nil, f.holdErr
+	}
+	holder, ok := f.holders[req.GetId()]
+	if !ok || holder.token != req.GetToken() {
+		return nil, status.Errorf(codes.FailedPrecondition, "lock %s is not held by this token", req.GetId())
+	}
+	return &api.HoldResponse{Id: req.GetId(), FencingToken: holder.fencing}, nil
+}
+
+func (f *Locks) Release(ctx context.Context, req *api.ReleaseRequest, opts ...grpc.CallOption) (*api.ReleaseResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	holder, ok := f.holders[req.GetId()]
+	if !ok || holder.token != req.GetToken() {
+		return nil, status.Errorf(codes.FailedPrecondition, "lock %s is not held by this token", req.GetId())
+	}
+	delete(f.holders, req.GetId())
+	f.releases++
+	return &api.ReleaseResponse{Id: req.GetId()}, nil
+}
+
+func (f *Locks) List(ctx context.Context, req *api.ListLocksRequest, opts ...grpc.CallOption) (api.Locks_ListClient, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stream := &listLocksClient{}
+	for id, holder := range f.holders {
+		if strings.HasPrefix(id, req.GetPrefix()) {
+			stream.locks = append(stream.locks, &api.LockInfo{Id: id, Holder: holder.name, Holders: 1, FencingToken: holder.fencing})
+		}
+	}
+	sort.Slice(stream.locks, func(i, j int) bool { return stream.locks[i].GetId() < stream.locks[j].GetId() })
+	return stream, nil
+}
+
+// Break simulates the lock being taken over by someone else
+func (f *Locks) Break(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.holders, id)
+}
+
+// SetHoldErr makes all following renewals fail with err
+func (f *Locks) SetHoldErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.holdErr = err
+}
+
+// Released returns true if a lock has been released by its holder
+func (f *Locks) Released() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.releases > 0
+}
+
+type listLocksClient struct {
+	grpc.ClientStream
+	locks []*api.LockInfo
+}
+
+func (s *listLocksClient) Recv() (*api.LockInfo, error) {
+	if len(s.locks) == 0 {
+		return nil, io.EOF
+	}
+	info := s.locks[0]
+	s.locks = s.locks[1:]
+	return info, nil
+}
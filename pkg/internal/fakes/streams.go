@@ -0,0 +1,108 @@
+package fakes
+
+import (
+	"context"
+	"io"
+
+	"github.com/trusch/backbone-tools/pkg/api"
+	"google.golang.org/grpc"
+)
+
+// SubscribeStream calls OnSend for every event sent by Subscribe
+type SubscribeStream struct {
+	grpc.ServerStream
+	Ctx    context.Context
+	OnSend func(*api.Event)
+}
+
+func (s *SubscribeStream) Context() context.Context {
+	return orBackground(s.Ctx)
+}
+
+func (s *SubscribeStream) Send(event *api.Event) error {
+	if s.OnSend != nil {
+		s.OnSend(event)
+	}
+	return nil
+}
+
+// ConsumeStream is a SubscribeStream which feeds the requests of Reqs to Consume
+type ConsumeStream struct {
+	SubscribeStream
+	Reqs chan *api.ConsumeRequest
+}
+
+func (s *ConsumeStream) Recv() (*api.ConsumeRequest, error) {
+	ctx := s.Context()
+	select {
+	case req := <-s.Reqs:
+		return req, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// PublishStream streams Reqs to PublishStream and keeps the response
+type PublishStream struct {
+	grpc.ServerStream
+	Ctx  context.Context
+	Reqs []*api.PublishRequest
+	Resp *api.PublishBatchResponse
+}
+
+func (s *PublishStream) Context() context.Context {
+	return orBackground(s.Ctx)
+}
+
+func (s *PublishStream) Recv() (*api.PublishRequest, error) {
+	if len(s.Reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.Reqs[0]
+	s.Reqs = s.Reqs[1:]
+	return req, nil
+}
+
+func (s *PublishStream) SendAndClose(resp *api.PublishBatchResponse) error {
+	s.Resp = resp
+	return nil
+}
+
+// ListSchemasStream collects the schemas sent by List
+type ListSchemasStream struct {
+	grpc.ServerStream
+	Ctx     context.Context
+	Schemas []*api.EventSchema
+}
+
+func (s *ListSchemasStream) Context() context.Context {
+	return orBackground(s.Ctx)
+}
+
+func (s *ListSchemasStream) Send(schema *api.EventSchema) error {
+	s.Schemas = append(s.Schemas, schema)
+	return nil
+}
+
+// ListLocksStream collects the locks sent by List
+type ListLocksStream struct {
+	grpc.ServerStream
+	Ctx   context.Context
+	Locks []*api.LockInfo
+}
+
+func (s *ListLocksStream) Context() context.Context {
+	return orBackground(s.Ctx)
+}
+
+func (s *ListLocksStream) Send(info *api.LockInfo) error {
+	s.Locks = append(s.Locks, info)
+	return nil
+}
+
+func orBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
@@ -0,0 +1,55 @@
+// Package testdb sets up postgres for integration tests.
+//
+// Tests run against the database in BACKBONE_TEST_DB (a local postgres by default)
+// and are skipped if it is not available. Every test works in its own schema.
+package testdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	// register the postgres driver
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/migrations"
+)
+
+// ConnectString returns the connect string of the test database
+func ConnectString() string {
+	if s := os.Getenv("BACKBONE_TEST_DB"); s != "" {
+		return s
+	}
+	return "postgres://localhost:5432?user=postgres&sslmode=disable"
+}
+
+// Empty opens the test database and returns the name of a fresh schema which doesn't exist yet.
+// The returned cleanup function drops the schema and closes the database.
+func Empty(tb testing.TB, prefix string) (db *sql.DB, schema string, cleanup func()) {
+	ctx := context.Background()
+	db, err := sql.Open("postgres", ConnectString())
+	require.NoError(tb, err)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		tb.Skipf("postgres not available: %v", err)
+	}
+	schema = fmt.Sprintf("%s_test_%d", prefix, time.Now().UnixNano())
+	cleanup = func() {
+		_, _ = db.ExecContext(ctx, `DROP SCHEMA IF EXISTS `+schema+` CASCADE`)
+		db.Close()
+	}
+	return db, schema, cleanup
+}
+
+// New is like Empty but applies all migrations to the schema
+func New(tb testing.TB, prefix string) (db *sql.DB, schema string, cleanup func()) {
+	db, schema, cleanup = Empty(tb, prefix)
+	if err := migrations.Up(context.Background(), db, schema); err != nil {
+		cleanup()
+		require.NoError(tb, err)
+	}
+	return db, schema, cleanup
+}
@@ -0,0 +1,141 @@
+package locks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultTTL is assumed if the server didn't report the granted ttl
+	defaultTTL = 20 * time.Second
+)
+
+// ErrLost is returned by Err when a lease could not be renewed and others may have taken over
+var ErrLost = errors.New("lease lost")
+
+// handle tracks the renewal of a lease
+type handle struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	release func(ctx context.Context) error
+	stopped chan struct{}
+
+	mu       sync.Mutex
+	err      error
+	released bool
+}
+
+func newHandle(ctx context.Context, release func(ctx context.Context) error) *handle {
+	h := &handle{
+		release: release,
+		stopped: make(chan struct{}),
+	}
+	h.ctx, h.cancel = context.WithCancel(ctx)
+	return h
+}
+
+// Context returns a context which is canceled as soon as the lease is lost or released.
+// Run the protected work with this context, so it stops when the lease is gone.
+func (h *handle) Context() context.Context {
+	return h.ctx
+}
+
+// Done is closed when the lease is lost or released
+func (h *handle) Done() <-chan struct{} {
+	return h.ctx.Done()
+}
+
+// Err returns ErrLost if the lease has been lost, the error of the acquisition context once it is done and nil otherwise
+func (h *handle) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return h.err
+	}
+	return h.ctx.Err()
+}
+
+// Release stops the renewal and releases the lease without waiting for the acquisition context.
+// It returns ErrLost if the lease has been lost before and nil if it has been released already.
+func (h *handle) Release(ctx context.Context) error {
+	first := h.claimRelease()
+	h.cancel()
+	<-h.stopped
+	if !first {
+		if h.Err() == ErrLost {
+			return ErrLost
+		}
+		return nil
+	}
+	return h.release(ctx)
+}
+
+// claimRelease returns true if the caller is the first one to release a lease which has not been lost
+func (h *handle) claimRelease() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.released || h.err == ErrLost {
+		return false
+	}
+	h.released = true
+	return true
+}
+
+func (h *handle) lost() {
+	h.mu.Lock()
+	h.err = ErrLost
+	h.mu.Unlock()
+	h.cancel()
+}
+
+// keepAlive renews a lease at a third of its ttl until the acquisition context is done and releases it afterwards.
+// The lease is lost when a renewal is rejected or renewals keep failing until the lease expires.
+func keepAlive(h *handle, name string, ttl time.Duration, expiresAt time.Time, renew func(ctx context.Context) error) {
+	defer close(h.stopped)
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.ctx.Done():
+			if !h.claimRelease() {
+				return
+			}
+			if err := h.release(context.Background()); err != nil {
+				logrus.Error(err)
+			}
+			return
+		case <-time.After(time.Until(expiresAt)):
+			logrus.Warnf("lost %s: lease expired", name)
+			h.lost()
+			// the server most likely expired the lease as well, release it in case it didn't
+			if err := h.release(context.Background()); err != nil && status.Code(err) != codes.FailedPrecondition {
+				logrus.Error(err)
+			}
+		case <-ticker.C:
+			start := time.Now()
+			// a renewal which doesn't finish before the lease expires is useless
+			ctx, cancel := context.WithDeadline(h.ctx, expiresAt)
+			err := renew(ctx)
+			cancel()
+			if err == nil {
+				expiresAt = start.Add(ttl)
+				break
+			}
+			if h.ctx.Err() != nil {
+				break
+			}
+			if status.Code(err) == codes.FailedPrecondition {
+				logrus.Warnf("lost %s: %v", name, err)
+				h.lost()
+				break
+			}
+			logrus.Warnf("failed to renew %s, retrying: %v", name, err)
+		}
+	}
+}
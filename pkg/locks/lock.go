@@ -4,40 +4,122 @@ import (
 	"context"
 	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/trusch/backbone-tools/pkg/api"
-	"github.com/sirupsen/logrus"
 )
 
-const (
-	renewInterval = 5 * time.Second
-)
+// Option modifies the acquisition request
+type Option func(req *api.AquireRequest)
+
+// WithTTL asks the server to keep the lock for the given duration without renewal.
+// The lock is renewed at a third of the ttl.
+func WithTTL(ttl time.Duration) Option {
+	return func(req *api.AquireRequest) {
+		req.Ttl = ptypes.DurationProto(ttl)
+	}
+}
+
+// WithHolder names the acquiring client, admins see the name when listing locks
+func WithHolder(name string) Option {
+	return func(req *api.AquireRequest) {
+		req.Holder = name
+	}
+}
+
+func withMode(mode api.LockMode) Option {
+	return func(req *api.AquireRequest) {
+		req.Mode = mode
+	}
+}
+
+func newRequest(id string, opts []Option) *api.AquireRequest {
+	req := &api.AquireRequest{Id: id}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req
+}
+
+// Lease describes a held lock.
+// Its context is canceled when the lock is lost, e.g. because renewals failed for longer than the ttl.
+type Lease struct {
+	*handle
+
+	ID string
+	// Token identifies the owner of the lock
+	Token string
+	// FencingToken increases with every acquisition of the lock.
+	// Pass it along with writes to downstream systems so they can reject writes of stale owners.
+	FencingToken uint64
+	// TTL is the time the lock is kept without renewal
+	TTL time.Duration
+	// Shared is true for locks taken with RLock
+	Shared bool
+}
 
 // Lock creates a lock and holds it until the context expires or is canceled
-// if the lock is not available it will block until the lock can be taken or the context is canceled
-func Lock(ctx context.Context, cli api.LocksClient, id string) error {
-	_, err := cli.Aquire(ctx, &api.AquireRequest{Id: id})
+// if the lock is not available it will block until the lock can be taken or the context is canceled.
+// Do the protected work with lease.Context(), it is canceled when the lock is lost.
+func Lock(ctx context.Context, cli api.LocksClient, id string, opts ...Option) (*Lease, error) {
+	resp, err := cli.Aquire(ctx, newRequest(id, opts))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	go func() {
-		ticker := time.NewTicker(renewInterval)
-		defer ticker.Stop()
-		if err != nil {
-			logrus.Error(err)
-			return
-		}
-		for {
-			select {
-			case <-ctx.Done():
-				_, err = cli.Release(context.Background(), &api.ReleaseRequest{Id: id})
-				if err != nil {
-					logrus.Error(err)
-				}
-				return
-			case <-ticker.C:
-				_, err = cli.Hold(ctx, &api.HoldRequest{Id: id})
-			}
-		}
-	}()
-	return nil
+	return hold(ctx, cli, resp), nil
+}
+
+// RLock takes the lock in shared mode like Lock does.
+// Any number of shared holders may hold the lock at the same time, but no exclusive holder.
+// Waiters are served in request order, so new shared holders queue up behind waiting exclusive ones.
+func RLock(ctx context.Context, cli api.LocksClient, id string, opts ...Option) (*Lease, error) {
+	return Lock(ctx, cli, id, append(opts, withMode(api.LockMode_SHARED))...)
+}
+
+// LockTimeout is like Lock but gives up with codes.DeadlineExceeded if the lock
+// could not be taken within the given timeout
+func LockTimeout(ctx context.Context, cli api.LocksClient, id string, timeout time.Duration, opts ...Option) (*Lease, error) {
+	req := newRequest(id, opts)
+	req.WaitTimeout = ptypes.DurationProto(timeout)
+	resp, err := cli.Aquire(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return hold(ctx, cli, resp), nil
+}
+
+// TryLock tries to take the lock without waiting.
+// If the lock is held by someone else the returned lease is nil and the info describes the current holder.
+func TryLock(ctx context.Context, cli api.LocksClient, id string, opts ...Option) (*Lease, *api.LockInfo, error) {
+	resp, err := cli.TryAquire(ctx, newRequest(id, opts))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !resp.GetAcquired() {
+		return nil, resp.GetHolder(), nil
+	}
+	return hold(ctx, cli, resp.GetLock()), nil, nil
+}
+
+// hold keeps the lock alive until the context is done and releases it afterwards.
+// It is called once the server granted the lock, which may be long after the acquisition has been requested.
+func hold(ctx context.Context, cli api.LocksClient, resp *api.AquireResponse) *Lease {
+	lease := &Lease{
+		ID:           resp.GetId(),
+		Token:        resp.GetToken(),
+		FencingToken: resp.GetFencingToken(),
+		TTL:          defaultTTL,
+		Shared:       resp.GetMode() == api.LockMode_SHARED,
+	}
+	if ttl, err := ptypes.Duration(resp.GetTtl()); err == nil && ttl > 0 {
+		lease.TTL = ttl
+	}
+	lease.handle = newHandle(ctx, func(ctx context.Context) error {
+		_, err := cli.Release(ctx, &api.ReleaseRequest{Id: lease.ID, Token: lease.Token})
+		return err
+	})
+	go keepAlive(lease.handle, "lock "+lease.ID, lease.TTL, time.Now().Add(lease.TTL), func(ctx context.Context) error {
+		_, err := cli.Hold(ctx, &api.HoldRequest{Id: lease.ID, Token: lease.Token})
+		return err
+	})
+	return lease
 }
@@ -0,0 +1,90 @@
+package locks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/internal/fakes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLeaseRenewsAndReleases(t *testing.T) {
+	cli := &fakes.Locks{}
+	ctx, cancel := context.WithCancel(context.Background())
+	lease, err := Lock(ctx, cli, "l1", WithTTL(300*time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, 300*time.Millisecond, lease.TTL)
+
+	// renewals keep the lease alive beyond its ttl
+	time.Sleep(time.Second)
+	require.NoError(t, lease.Err())
+
+	cancel()
+	<-lease.Done()
+	require.Equal(t, context.Canceled, lease.Err())
+	require.Eventually(t, cli.Released, time.Second, 10*time.Millisecond)
+}
+
+func TestLeaseLostOnRejectedRenewal(t *testing.T) {
+	cli := &fakes.Locks{}
+	lease, err := Lock(context.Background(), cli, "l1", WithTTL(300*time.Millisecond))
+	require.NoError(t, err)
+
+	cli.SetHoldErr(status.Error(codes.FailedPrecondition, "not owner"))
+	select {
+	case <-lease.Done():
+	case <-time.After(time.Second):
+		t.Fatal("lease was not lost")
+	}
+	require.Equal(t, ErrLost, lease.Err())
+	require.Error(t, lease.Context().Err())
+	require.False(t, cli.Released())
+}
+
+func TestLeaseLostOnExpiry(t *testing.T) {
+	cli := &fakes.Locks{}
+	cli.SetHoldErr(status.Error(codes.Unavailable, "server down"))
+	start := time.Now()
+	lease, err := Lock(context.Background(), cli, "l1", WithTTL(300*time.Millisecond))
+	require.NoError(t, err)
+
+	// transient errors are retried until the lease expires
+	select {
+	case <-lease.Done():
+		require.True(t, time.Since(start) >= 300*time.Millisecond)
+	case <-time.After(2 * time.Second):
+		t.Fatal("lease was not lost")
+	}
+	require.Equal(t, ErrLost, lease.Err())
+}
+
+func TestLeaseRelease(t *testing.T) {
+	cli := &fakes.Locks{}
+	lease, err := Lock(context.Background(), cli, "l1", WithTTL(300*time.Millisecond))
+	require.NoError(t, err)
+
+	// the lease is released without canceling the acquisition context
+	require.NoError(t, lease.Release(context.Background()))
+	require.True(t, cli.Released())
+	require.Error(t, lease.Context().Err())
+	require.NoError(t, lease.Release(context.Background()))
+
+	lost, err := Lock(context.Background(), cli, "l2", WithTTL(300*time.Millisecond))
+	require.NoError(t, err)
+	cli.SetHoldErr(status.Error(codes.FailedPrecondition, "not owner"))
+	<-lost.Done()
+	require.Equal(t, ErrLost, lost.Release(context.Background()))
+}
+
+func TestLeaseAfterLongWait(t *testing.T) {
+	// the lock is granted long after it has been requested, the lease starts with the grant
+	cli := &fakes.Locks{AquireDelay: 500 * time.Millisecond}
+	lease, err := Lock(context.Background(), cli, "l1", WithTTL(300*time.Millisecond))
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, lease.Err())
+	require.NoError(t, lease.Release(context.Background()))
+}
@@ -0,0 +1,50 @@
+package locks
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/trusch/backbone-tools/pkg/api"
+)
+
+// Permits describes permits held on a semaphore.
+// Its context is canceled when the permits are lost, e.g. because renewals failed for longer than the ttl.
+type Permits struct {
+	*handle
+
+	Name string
+	// Token identifies the holder of the permits
+	Token string
+	// Count is the number of held permits
+	Count uint32
+	// TTL is the time the permits are kept without renewal
+	TTL time.Duration
+}
+
+// AcquireSemaphore takes permits of a semaphore with max permits in total and holds them until the context expires or is canceled.
+// If not enough permits are available it will block until they are or the context is canceled.
+func AcquireSemaphore(ctx context.Context, cli api.SemaphoresClient, name string, permits, max uint32) (*Permits, error) {
+	resp, err := cli.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: name, Permits: permits, Max: max})
+	if err != nil {
+		return nil, err
+	}
+	held := &Permits{
+		Name:  resp.GetName(),
+		Token: resp.GetToken(),
+		Count: resp.GetPermits(),
+		TTL:   defaultTTL,
+	}
+	if ttl, err := ptypes.Duration(resp.GetTtl()); err == nil && ttl > 0 {
+		held.TTL = ttl
+	}
+	held.handle = newHandle(ctx, func(ctx context.Context) error {
+		_, err := cli.Release(ctx, &api.SemaphoreReleaseRequest{Name: held.Name, Token: held.Token})
+		return err
+	})
+	go keepAlive(held.handle, "semaphore "+held.Name, held.TTL, time.Now().Add(held.TTL), func(ctx context.Context) error {
+		_, err := cli.Hold(ctx, &api.SemaphoreHoldRequest{Name: held.Name, Token: held.Token})
+		return err
+	})
+	return held, nil
+}
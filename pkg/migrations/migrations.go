@@ -0,0 +1,167 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/trusch/backbone-tools/pkg/sqlizers"
+)
+
+// advisoryLockKey serializes migration runs of concurrently starting replicas
+const advisoryLockKey = 0x6261636b626f6e65 // "backbone"
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes a known migration and whether it has been applied
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Up applies all pending migrations
+func Up(ctx context.Context, db *sql.DB, schema string) error {
+	return withLock(ctx, db, schema, func(tx *sql.Tx) error {
+		applied, err := appliedVersions(ctx, tx, "schema_migrations")
+		if err != nil {
+			return err
+		}
+		for _, m := range All {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			logrus.Infof("applying migration %d (%s)", m.Version, m.Name)
+			if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+				return errors.Wrapf(err, "failed to apply migration %d (%s)", m.Version, m.Name)
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations(version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the given number of most recently applied migrations
+func Down(ctx context.Context, db *sql.DB, schema string, steps int) error {
+	return withLock(ctx, db, schema, func(tx *sql.Tx) error {
+		applied, err := appliedVersions(ctx, tx, "schema_migrations")
+		if err != nil {
+			return err
+		}
+		for i := len(All) - 1; i >= 0 && steps > 0; i-- {
+			m := All[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			logrus.Infof("reverting migration %d (%s)", m.Version, m.Name)
+			if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+				return errors.Wrapf(err, "failed to revert migration %d (%s)", m.Version, m.Name)
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// List returns all known migrations together with the time they were applied.
+// It doesn't change the database, all migrations are pending if the schema has not been migrated yet.
+func List(ctx context.Context, db *sql.DB, schema string) ([]Status, error) {
+	table := sqlizers.Table(schema, "schema_migrations")
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]time.Time)
+	if exists {
+		var err error
+		applied, err = appliedVersions(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+	}
+	res := make([]Status, 0, len(All))
+	for _, m := range All {
+		st := Status{Migration: m}
+		if appliedAt, ok := applied[m.Version]; ok {
+			st.AppliedAt = &appliedAt
+		}
+		res = append(res, st)
+	}
+	return res, nil
+}
+
+// withLock runs fn in a transaction holding the migration advisory lock.
+// If a schema is given it is created if needed and used as search_path for the transaction.
+func withLock(ctx context.Context, db *sql.DB, schema string, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, advisoryLockKey); err != nil {
+		return err
+	}
+	if schema != "" {
+		quoted := pgx.Identifier{schema}.Sanitize()
+		if _, err = tx.ExecContext(ctx, `CREATE SCHEMA IF NOT EXISTS `+quoted); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, `SET LOCAL search_path TO `+quoted); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations(
+  version INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+`)
+	if err != nil {
+		return err
+	}
+	return fn(tx)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func appliedVersions(ctx context.Context, db queryer, table string) (map[int]time.Time, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, applied_at FROM `+table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		res[version] = appliedAt
+	}
+	return res, rows.Err()
+}
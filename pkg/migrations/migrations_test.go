@@ -0,0 +1,46 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/internal/testdb"
+	"github.com/trusch/backbone-tools/pkg/migrations"
+)
+
+func pending(t *testing.T, db *sql.DB, schema string) (versions []int) {
+	list, err := migrations.List(context.Background(), db, schema)
+	require.NoError(t, err)
+	require.Len(t, list, len(migrations.All))
+	for _, st := range list {
+		if st.AppliedAt == nil {
+			versions = append(versions, st.Version)
+		}
+	}
+	return versions
+}
+
+func TestUpDownUp(t *testing.T) {
+	db, schema, cleanup := testdb.Empty(t, "migrations")
+	defer cleanup()
+	ctx := context.Background()
+	last := migrations.All[len(migrations.All)-1]
+
+	// listing doesn't create the schema
+	require.Len(t, pending(t, db, schema), len(migrations.All))
+	var schemas int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM pg_namespace WHERE nspname = $1`, schema).Scan(&schemas))
+	require.Equal(t, 0, schemas)
+
+	require.NoError(t, migrations.Up(ctx, db, schema))
+	require.Empty(t, pending(t, db, schema))
+
+	// the down migration of the latest version reverts it completely, so it can be applied again
+	require.NoError(t, migrations.Down(ctx, db, schema, 1))
+	require.Equal(t, []int{last.Version}, pending(t, db, schema))
+
+	require.NoError(t, migrations.Up(ctx, db, schema))
+	require.Empty(t, pending(t, db, schema))
+}
@@ -0,0 +1,337 @@
+package migrations
+
+// All contains all schema migrations in the order they are applied.
+// Never change a migration which has been released, append a new one instead.
+var All = []Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		// IF NOT EXISTS keeps this compatible with deployments created before migrations existed
+		Up: `
+CREATE TABLE IF NOT EXISTS jobs(
+  job_id UUID PRIMARY KEY,
+  queue TEXT NOT NULL,
+  spec BYTEA,
+  labels JSONB NOT NULL DEFAULT '{}',
+  state BYTEA,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS cronjobs(
+  cronjob_id UUID PRIMARY KEY,
+  queue TEXT NOT NULL,
+  name TEXT UNIQUE,
+  spec BYTEA,
+  cron TEXT,
+  labels JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  next_run_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS locks(
+  lock_id TEXT PRIMARY KEY,
+  updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS events(
+  event_id UUID PRIMARY KEY,
+  topic TEXT NOT NULL,
+  payload BYTEA,
+  labels JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  sequence SERIAL
+);
+`,
+		Down: `
+DROP TABLE events;
+DROP TABLE locks;
+DROP TABLE cronjobs;
+DROP TABLE jobs;
+`,
+	},
+	{
+		Version: 2,
+		Name:    "indexes for hot query paths",
+		Up: `
+-- claiming jobs only looks at unfinished jobs of a single queue ordered by creation time
+CREATE INDEX IF NOT EXISTS jobs_claim_idx ON jobs (queue, created_at) WHERE finished_at IS NULL;
+CREATE INDEX IF NOT EXISTS jobs_queue_created_at_idx ON jobs (queue, created_at);
+CREATE INDEX IF NOT EXISTS jobs_labels_idx ON jobs USING GIN (labels jsonb_path_ops);
+
+CREATE INDEX IF NOT EXISTS cronjobs_next_run_at_idx ON cronjobs (next_run_at);
+CREATE INDEX IF NOT EXISTS cronjobs_labels_idx ON cronjobs USING GIN (labels jsonb_path_ops);
+
+CREATE INDEX IF NOT EXISTS events_topic_sequence_idx ON events (topic, sequence);
+CREATE INDEX IF NOT EXISTS events_topic_created_at_idx ON events (topic, created_at);
+CREATE INDEX IF NOT EXISTS events_labels_idx ON events USING GIN (labels jsonb_path_ops);
+`,
+		Down: `
+DROP INDEX events_labels_idx;
+DROP INDEX events_topic_created_at_idx;
+DROP INDEX events_topic_sequence_idx;
+DROP INDEX cronjobs_labels_idx;
+DROP INDEX cronjobs_next_run_at_idx;
+DROP INDEX jobs_labels_idx;
+DROP INDEX jobs_queue_created_at_idx;
+DROP INDEX jobs_claim_idx;
+`,
+	},
+	{
+		Version: 3,
+		Name:    "namespaces",
+		Up: `
+ALTER TABLE jobs ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE cronjobs ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE locks ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE events ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE cronjobs DROP CONSTRAINT cronjobs_name_key, ADD CONSTRAINT cronjobs_namespace_name_key UNIQUE (namespace, name);
+ALTER TABLE locks DROP CONSTRAINT locks_pkey, ADD PRIMARY KEY (namespace, lock_id);
+
+DROP INDEX jobs_claim_idx;
+DROP INDEX jobs_queue_created_at_idx;
+DROP INDEX events_topic_sequence_idx;
+DROP INDEX events_topic_created_at_idx;
+CREATE INDEX jobs_claim_idx ON jobs (namespace, queue, created_at) WHERE finished_at IS NULL;
+CREATE INDEX jobs_queue_created_at_idx ON jobs (namespace, queue, created_at);
+CREATE INDEX events_topic_sequence_idx ON events (namespace, topic, sequence);
+CREATE INDEX events_topic_created_at_idx ON events (namespace, topic, created_at);
+`,
+		Down: `
+DROP INDEX events_topic_created_at_idx;
+DROP INDEX events_topic_sequence_idx;
+DROP INDEX jobs_queue_created_at_idx;
+DROP INDEX jobs_claim_idx;
+CREATE INDEX jobs_claim_idx ON jobs (queue, created_at) WHERE finished_at IS NULL;
+CREATE INDEX jobs_queue_created_at_idx ON jobs (queue, created_at);
+CREATE INDEX events_topic_sequence_idx ON events (topic, sequence);
+CREATE INDEX events_topic_created_at_idx ON events (topic, created_at);
+
+ALTER TABLE locks DROP CONSTRAINT locks_pkey, ADD PRIMARY KEY (lock_id);
+ALTER TABLE cronjobs DROP CONSTRAINT cronjobs_namespace_name_key, ADD CONSTRAINT cronjobs_name_key UNIQUE (name);
+
+ALTER TABLE events DROP COLUMN namespace;
+ALTER TABLE locks DROP COLUMN namespace;
+ALTER TABLE cronjobs DROP COLUMN namespace;
+ALTER TABLE jobs DROP COLUMN namespace;
+`,
+	},
+	{
+		Version: 4,
+		Name:    "lock ownership and fencing tokens",
+		Up: `
+ALTER TABLE locks ADD COLUMN owner_token TEXT;
+ALTER TABLE locks ADD COLUMN fencing_token BIGINT NOT NULL DEFAULT 0;
+`,
+		Down: `
+ALTER TABLE locks DROP COLUMN fencing_token;
+ALTER TABLE locks DROP COLUMN owner_token;
+`,
+	},
+	{
+		Version: 5,
+		Name:    "per acquisition lock ttl",
+		Up: `
+ALTER TABLE locks ADD COLUMN ttl_ms BIGINT NOT NULL DEFAULT 20000;
+ALTER TABLE locks ADD COLUMN expires_at TIMESTAMPTZ;
+UPDATE locks SET expires_at = updated_at + ttl_ms * INTERVAL '1 millisecond';
+`,
+		Down: `
+ALTER TABLE locks DROP COLUMN expires_at;
+ALTER TABLE locks DROP COLUMN ttl_ms;
+`,
+	},
+	{
+		Version: 6,
+		Name:    "lock holders for shared locks",
+		Up: `
+CREATE TABLE lock_holders(
+  namespace TEXT NOT NULL,
+  lock_id TEXT NOT NULL,
+  owner_token TEXT NOT NULL,
+  shared BOOLEAN NOT NULL DEFAULT false,
+  fencing_token BIGINT NOT NULL,
+  ttl_ms BIGINT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (namespace, lock_id, owner_token),
+  FOREIGN KEY (namespace, lock_id) REFERENCES locks (namespace, lock_id) ON DELETE CASCADE
+);
+
+INSERT INTO lock_holders (namespace, lock_id, owner_token, fencing_token, ttl_ms, updated_at, expires_at)
+  SELECT namespace, lock_id, owner_token, fencing_token, ttl_ms, updated_at, expires_at
+  FROM locks WHERE owner_token IS NOT NULL AND expires_at IS NOT NULL;
+
+ALTER TABLE locks DROP COLUMN owner_token;
+ALTER TABLE locks DROP COLUMN ttl_ms;
+ALTER TABLE locks DROP COLUMN expires_at;
+-- set by waiting exclusive acquirers to keep new shared holders out
+ALTER TABLE locks ADD COLUMN writer_waiting_until TIMESTAMPTZ;
+`,
+		Down: `
+ALTER TABLE locks DROP COLUMN writer_waiting_until;
+ALTER TABLE locks ADD COLUMN owner_token TEXT;
+ALTER TABLE locks ADD COLUMN ttl_ms BIGINT NOT NULL DEFAULT 20000;
+ALTER TABLE locks ADD COLUMN expires_at TIMESTAMPTZ;
+
+UPDATE locks SET owner_token = h.owner_token, ttl_ms = h.ttl_ms, expires_at = h.expires_at
+  FROM lock_holders h
+  WHERE h.namespace = locks.namespace AND h.lock_id = locks.lock_id AND NOT h.shared;
+
+DROP TABLE lock_holders;
+`,
+	},
+	{
+		Version: 7,
+		Name:    "semaphores",
+		Up: `
+CREATE TABLE semaphore_holders(
+  namespace TEXT NOT NULL,
+  name TEXT NOT NULL,
+  owner_token TEXT NOT NULL,
+  permits INTEGER NOT NULL CHECK (permits > 0),
+  ttl_ms BIGINT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (namespace, name, owner_token)
+);
+CREATE TABLE semaphores(
+  namespace TEXT NOT NULL,
+  name TEXT NOT NULL,
+  max INTEGER NOT NULL CHECK (max > 0),
+  PRIMARY KEY (namespace, name)
+);
+`,
+		Down: `
+DROP TABLE semaphores;
+DROP TABLE semaphore_holders;
+`,
+	},
+	{
+		Version: 8,
+		Name:    "fifo lock waiters",
+		Up: `
+CREATE TABLE lock_waiters(
+  waiter_id TEXT PRIMARY KEY,
+  namespace TEXT NOT NULL,
+  lock_id TEXT NOT NULL,
+  shared BOOLEAN NOT NULL DEFAULT false,
+  seq BIGSERIAL NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (namespace, lock_id) REFERENCES locks (namespace, lock_id) ON DELETE CASCADE
+);
+CREATE INDEX lock_waiters_queue_idx ON lock_waiters (namespace, lock_id, seq);
+
+-- the waiter queue replaces the writer preference marker
+ALTER TABLE locks DROP COLUMN writer_waiting_until;
+`,
+		Down: `
+ALTER TABLE locks ADD COLUMN writer_waiting_until TIMESTAMPTZ;
+DROP TABLE lock_waiters;
+`,
+	},
+	{
+		Version: 9,
+		Name:    "lock holder identity",
+		Up: `
+ALTER TABLE lock_holders ADD COLUMN holder TEXT NOT NULL DEFAULT '';
+ALTER TABLE lock_holders ADD COLUMN holder_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE lock_holders ADD COLUMN acquired_at TIMESTAMPTZ NOT NULL DEFAULT now();
+`,
+		Down: `
+ALTER TABLE lock_holders DROP COLUMN acquired_at;
+ALTER TABLE lock_holders DROP COLUMN holder_address;
+ALTER TABLE lock_holders DROP COLUMN holder;
+`,
+	},
+	{
+		Version: 10,
+		Name:    "consumer groups",
+		Up: `
+CREATE TABLE consumer_groups(
+  namespace TEXT NOT NULL,
+  group_name TEXT NOT NULL,
+  topic TEXT NOT NULL,
+  committed_sequence BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (namespace, group_name, topic)
+);
+`,
+		Down: `
+DROP TABLE consumer_groups;
+`,
+	},
+	{
+		Version: 11,
+		Name:    "event acknowledgements",
+		Up: `
+-- events up to claimed_sequence have been handed out, events up to committed_sequence are acknowledged
+ALTER TABLE consumer_groups ADD COLUMN claimed_sequence BIGINT NOT NULL DEFAULT 0;
+UPDATE consumer_groups SET claimed_sequence = committed_sequence;
+
+CREATE TABLE event_deliveries(
+  namespace TEXT NOT NULL,
+  group_name TEXT NOT NULL,
+  topic TEXT NOT NULL,
+  sequence BIGINT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 1,
+  delivered_at TIMESTAMPTZ NOT NULL,
+  visible_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (namespace, group_name, topic, sequence),
+  FOREIGN KEY (namespace, group_name, topic) REFERENCES consumer_groups (namespace, group_name, topic) ON DELETE CASCADE
+);
+`,
+		Down: `
+DROP TABLE event_deliveries;
+ALTER TABLE consumer_groups DROP COLUMN claimed_sequence;
+`,
+	},
+	{
+		Version: 12,
+		Name:    "topic sequences",
+		Up: `
+-- dense per topic numbering of events, allocated under the publish lock of the namespace
+ALTER TABLE events ADD COLUMN topic_sequence BIGINT;
+UPDATE events SET topic_sequence = numbered.n
+  FROM (SELECT event_id, row_number() OVER (PARTITION BY namespace, topic ORDER BY sequence) AS n FROM events) numbered
+  WHERE events.event_id = numbered.event_id;
+ALTER TABLE events ALTER COLUMN topic_sequence SET NOT NULL;
+CREATE UNIQUE INDEX events_topic_sequence_unique_idx ON events (namespace, topic, topic_sequence);
+
+CREATE TABLE topic_sequences(
+  namespace TEXT NOT NULL,
+  topic TEXT NOT NULL,
+  last_sequence BIGINT NOT NULL,
+  PRIMARY KEY (namespace, topic)
+);
+INSERT INTO topic_sequences (namespace, topic, last_sequence)
+  SELECT namespace, topic, MAX(topic_sequence) FROM events GROUP BY namespace, topic;
+`,
+		Down: `
+DROP TABLE topic_sequences;
+DROP INDEX events_topic_sequence_unique_idx;
+ALTER TABLE events DROP COLUMN topic_sequence;
+`,
+	},
+	{
+		Version: 13,
+		Name:    "event schemas",
+		Up: `
+CREATE TABLE event_schemas(
+  namespace TEXT NOT NULL,
+  topic TEXT NOT NULL,
+  version INTEGER NOT NULL CHECK (version > 0),
+  schema TEXT NOT NULL,
+  compatibility TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (namespace, topic, version)
+);
+`,
+		Down: `
+DROP TABLE event_schemas;
+`,
+	},
+}
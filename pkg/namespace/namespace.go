@@ -0,0 +1,63 @@
+package namespace
+
+import (
+	"context"
+
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MetadataKey is the gRPC metadata key carrying the namespace of a request
+	MetadataKey = "backbone-namespace"
+
+	// Default is the namespace used if a request doesn't specify one
+	Default = "default"
+)
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the given namespace.
+// It takes precedence over the namespace in the incoming metadata and is used for server internal calls.
+func NewContext(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, contextKey{}, namespace)
+}
+
+// FromContext returns the validated namespace of a request
+func FromContext(ctx context.Context) (string, error) {
+	ns, ok := ctx.Value(contextKey{}).(string)
+	if !ok {
+		ns = Default
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(MetadataKey); len(values) > 0 && values[0] != "" {
+				ns = values[0]
+			}
+		}
+	}
+	if err := channels.ValidateName("namespace", ns); err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return ns, nil
+}
+
+// NewOutgoingContext attaches the namespace to the metadata of outgoing requests
+func NewOutgoingContext(ctx context.Context, namespace string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, namespace)
+}
+
+// UnaryClientInterceptor sends all unary requests in the given namespace
+func UnaryClientInterceptor(namespace string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(NewOutgoingContext(ctx, namespace), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor sends all streaming requests in the given namespace
+func StreamClientInterceptor(namespace string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(NewOutgoingContext(ctx, namespace), desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,340 @@
+// Package schemas validates event payloads with JSON schemas and checks the compatibility of schema versions.
+//
+// Compatibility is checked structurally on the keywords type, enum, properties, required, additionalProperties and items.
+// Other validation keywords are compatible only if they are unchanged. Keywords which allow more values, like
+// patternProperties or $ref, have to be equal in both versions, so the check may reject compatible changes
+// but never accepts incompatible ones.
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// annotations don't restrict payloads and are ignored by the compatibility check
+var annotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+}
+
+// structural keywords are compared by subset, all others have to be equal
+var structural = map[string]bool{
+	"type":                 true,
+	"enum":                 true,
+	"properties":           true,
+	"required":             true,
+	"additionalProperties": true,
+	"items":                true,
+}
+
+// expanding keywords allow values which other keywords reject, they have to be equal in both schemas
+var expanding = []string{"$ref", "definitions", "patternProperties"}
+
+// Compile parses a JSON schema document. Only references into the document itself are allowed,
+// so compiling never fetches urls or reads files.
+func Compile(doc string) (*gojsonschema.Schema, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		return nil, err
+	}
+	if err := localRefs(v, "$"); err != nil {
+		return nil, err
+	}
+	return gojsonschema.NewSchema(gojsonschema.NewStringLoader(doc))
+}
+
+// localRefs returns an error if a $ref points outside of the document
+func localRefs(v interface{}, path string) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && !strings.HasPrefix(ref, "#") {
+			return fmt.Errorf("%s: only references within the schema are allowed, not %s", path, ref)
+		}
+		for _, key := range sortedKeys(v) {
+			if err := localRefs(v[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if err := localRefs(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks a JSON payload against a schema
+func Validate(schema *gojsonschema.Schema, payload []byte) error {
+	res, err := schema.Validate(gojsonschema.NewBytesLoader(payload))
+	if err != nil {
+		return errors.Wrap(err, "payload is not valid JSON")
+	}
+	if res.Valid() {
+		return nil
+	}
+	var msgs []string
+	for _, e := range res.Errors() {
+		msgs = append(msgs, e.String())
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// Check returns an error describing why the next version of a schema is not compatible to the previous one
+func Check(previous, next string, mode api.SchemaCompatibility) error {
+	var prev, nxt interface{}
+	if err := json.Unmarshal([]byte(previous), &prev); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(next), &nxt); err != nil {
+		return err
+	}
+	switch mode {
+	case api.SchemaCompatibility_BACKWARD:
+		return errors.Wrap(subset(prev, nxt, "$"), "payloads of the previous version are rejected")
+	case api.SchemaCompatibility_FORWARD:
+		return errors.Wrap(subset(nxt, prev, "$"), "payloads of the new version are rejected by the previous version")
+	case api.SchemaCompatibility_FULL:
+		if err := Check(previous, next, api.SchemaCompatibility_BACKWARD); err != nil {
+			return err
+		}
+		return Check(previous, next, api.SchemaCompatibility_FORWARD)
+	}
+	return nil
+}
+
+// subset returns nil if every instance valid under a is valid under b
+func subset(a, b interface{}, path string) error {
+	am, aFalse := asSchema(a)
+	bm, bFalse := asSchema(b)
+	switch {
+	case aFalse:
+		return nil
+	case bFalse:
+		return fmt.Errorf("%s: no values are allowed", path)
+	case reflect.DeepEqual(withoutAnnotations(am), withoutAnnotations(bm)):
+		return nil
+	}
+
+	if bTypes, ok := types(bm); ok {
+		aTypes, ok := types(am)
+		if !ok {
+			return fmt.Errorf("%s: only %s allowed", path, strings.Join(bTypes, ", "))
+		}
+		for _, t := range aTypes {
+			if !contains(bTypes, t) && !(t == "integer" && contains(bTypes, "number")) {
+				return fmt.Errorf("%s: type %s is not allowed", path, t)
+			}
+		}
+	}
+
+	if bEnum, ok := bm["enum"].([]interface{}); ok {
+		aEnum, ok := am["enum"].([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: only the values %v are allowed", path, bEnum)
+		}
+		for _, v := range aEnum {
+			if !containsValue(bEnum, v) {
+				return fmt.Errorf("%s: value %v is not allowed", path, v)
+			}
+		}
+	}
+
+	aRequired := stringSet(am["required"])
+	for name := range stringSet(bm["required"]) {
+		if !aRequired[name] {
+			return fmt.Errorf("%s.%s: property is required", path, name)
+		}
+	}
+
+	for _, keyword := range expanding {
+		if !reflect.DeepEqual(am[keyword], bm[keyword]) {
+			return fmt.Errorf("%s: %s changed", path, keyword)
+		}
+	}
+
+	aProps, _ := am["properties"].(map[string]interface{})
+	bProps, _ := bm["properties"].(map[string]interface{})
+	for _, name := range sortedKeys(bProps) {
+		a, ok := aProps[name]
+		if !ok {
+			schemas, err := unlisted(am, name)
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			// a property matching several patterns has to be valid under all of them, allowing anything is a safe superset
+			a = true
+			if len(schemas) == 1 {
+				a = schemas[0]
+			}
+		}
+		if err := subset(a, bProps[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(aProps) {
+		if _, ok := bProps[name]; ok {
+			continue
+		}
+		schemas, err := unlisted(bm, name)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		for _, b := range schemas {
+			if err := subset(aProps[name], b, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	// patternProperties are equal, so only properties matching no pattern are validated by additionalProperties
+	if err := subset(additional(am), additional(bm), path+".*"); err != nil {
+		return err
+	}
+
+	if bItems, ok := bm["items"]; ok {
+		if _, tuple := bItems.([]interface{}); !tuple {
+			aItems, ok := am["items"]
+			if !ok {
+				aItems = true
+			}
+			if err := subset(aItems, bItems, path+"[]"); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range sortedKeys(bm) {
+		if annotations[keyword] || (structural[keyword] && !isTuple(keyword, bm[keyword])) {
+			continue
+		}
+		if !reflect.DeepEqual(am[keyword], bm[keyword]) {
+			return fmt.Errorf("%s: %s changed", path, keyword)
+		}
+	}
+	return nil
+}
+
+// asSchema returns the keywords of a schema and whether it rejects everything
+func asSchema(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case bool:
+		return map[string]interface{}{}, !v
+	case map[string]interface{}:
+		if not, ok := v["not"].(map[string]interface{}); ok && len(not) == 0 {
+			return v, true
+		}
+		return v, false
+	}
+	return map[string]interface{}{}, false
+}
+
+func withoutAnnotations(m map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if !annotations[k] {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// additional returns the schema of properties not listed in properties
+func additional(m map[string]interface{}) interface{} {
+	if v, ok := m["additionalProperties"]; ok {
+		return v
+	}
+	return true
+}
+
+// unlisted returns the schemas validating a property which is not listed in properties:
+// the schemas of all matching patternProperties or additionalProperties if no pattern matches
+func unlisted(m map[string]interface{}, name string) ([]interface{}, error) {
+	patterns, _ := m["patternProperties"].(map[string]interface{})
+	var res []interface{}
+	for _, pattern := range sortedKeys(patterns) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unsupported pattern %s", pattern)
+		}
+		if re.MatchString(name) {
+			res = append(res, patterns[pattern])
+		}
+	}
+	if len(res) == 0 {
+		res = append(res, additional(m))
+	}
+	return res, nil
+}
+
+func types(m map[string]interface{}) ([]string, bool) {
+	switch t := m["type"].(type) {
+	case string:
+		return []string{t}, true
+	case []interface{}:
+		var res []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+func isTuple(keyword string, v interface{}) bool {
+	_, ok := v.([]interface{})
+	return keyword == "items" && ok
+}
+
+func stringSet(v interface{}) map[string]bool {
+	res := make(map[string]bool)
+	list, _ := v.([]interface{})
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			res[s] = true
+		}
+	}
+	return res
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsValue(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
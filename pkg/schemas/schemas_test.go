@@ -0,0 +1,170 @@
+package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/api"
+)
+
+const order = `{
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer"},
+    "state": {"enum": ["new", "paid"]}
+  },
+  "required": ["id"]
+}`
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile(order)
+	require.NoError(t, err)
+	require.NoError(t, Validate(schema, []byte(`{"id": 1, "state": "new"}`)))
+	require.Error(t, Validate(schema, []byte(`{"state": "new"}`)))
+	require.Error(t, Validate(schema, []byte(`{"id": "1"}`)))
+	require.Error(t, Validate(schema, []byte(`not json`)))
+
+	_, err = Compile(`{"type": 42}`)
+	require.Error(t, err)
+}
+
+func TestCompileRejectsExternalRefs(t *testing.T) {
+	_, err := Compile(`{"definitions": {"id": {"type": "integer"}}, "properties": {"id": {"$ref": "#/definitions/id"}}}`)
+	require.NoError(t, err)
+
+	for _, ref := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"https://example.com/schema.json#/definitions/id",
+		"file:///etc/passwd",
+		"/etc/passwd",
+		"other.json",
+	} {
+		_, err := Compile(`{"properties": {"id": {"items": [{"$ref": "` + ref + `"}]}}}`)
+		require.Error(t, err, ref)
+		require.Contains(t, err.Error(), "$.properties.id.items[0]", ref)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, c := range []struct {
+		name          string
+		next          string
+		backward      bool
+		forward       bool
+		errorContains string
+	}{
+		{
+			name:     "unchanged except annotations",
+			next:     `{"title": "order", "type": "object", "properties": {"id": {"type": "integer"}, "state": {"enum": ["new", "paid"]}}, "required": ["id"]}`,
+			backward: true, forward: true,
+		},
+		{
+			name:     "optional property added",
+			next:     `{"type": "object", "properties": {"id": {"type": "integer"}, "state": {"enum": ["new", "paid"]}, "note": {"type": "string"}}, "required": ["id"]}`,
+			backward: false, forward: true,
+		},
+		{
+			name:     "required property added",
+			next:     `{"type": "object", "properties": {"id": {"type": "integer"}, "state": {"enum": ["new", "paid"]}, "note": {"type": "string"}}, "required": ["id", "note"]}`,
+			backward: false, forward: true,
+		},
+		{
+			name:     "requirement dropped",
+			next:     `{"type": "object", "properties": {"id": {"type": "integer"}, "state": {"enum": ["new", "paid"]}}}`,
+			backward: true, forward: false,
+		},
+		{
+			name:     "type widened",
+			next:     `{"type": "object", "properties": {"id": {"type": "number"}, "state": {"enum": ["new", "paid"]}}, "required": ["id"]}`,
+			backward: true, forward: false,
+		},
+		{
+			name:     "enum value added",
+			next:     `{"type": "object", "properties": {"id": {"type": "integer"}, "state": {"enum": ["new", "paid", "shipped"]}}, "required": ["id"]}`,
+			backward: true, forward: false,
+		},
+		{
+			name:          "type changed",
+			next:          `{"type": "object", "properties": {"id": {"type": "string"}, "state": {"enum": ["new", "paid"]}}, "required": ["id"]}`,
+			errorContains: "$.id: type integer is not allowed",
+		},
+		{
+			name:     "other keyword added",
+			next:     `{"type": "object", "properties": {"id": {"type": "integer", "minimum": 1}, "state": {"enum": ["new", "paid"]}}, "required": ["id"]}`,
+			backward: false, forward: true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := Check(order, c.next, api.SchemaCompatibility_BACKWARD)
+			require.Equal(t, c.backward, err == nil, "backward: %v", err)
+			if c.errorContains != "" {
+				require.Contains(t, err.Error(), c.errorContains)
+			}
+			err = Check(order, c.next, api.SchemaCompatibility_FORWARD)
+			require.Equal(t, c.forward, err == nil, "forward: %v", err)
+			err = Check(order, c.next, api.SchemaCompatibility_FULL)
+			require.Equal(t, c.backward && c.forward, err == nil, "full: %v", err)
+			require.NoError(t, Check(order, c.next, api.SchemaCompatibility_NONE))
+		})
+	}
+}
+
+func TestCheckExpandingKeywords(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		previous string
+		next     string
+		backward bool
+		forward  bool
+	}{
+		{
+			name:     "pattern properties removed",
+			previous: `{"patternProperties": {"^x": {"type": "string"}}, "additionalProperties": false}`,
+			next:     `{"additionalProperties": false}`,
+			backward: false, forward: false,
+		},
+		{
+			name:     "pattern properties added",
+			previous: `{"additionalProperties": false}`,
+			next:     `{"patternProperties": {"^x": {"type": "string"}}, "additionalProperties": false}`,
+			backward: false, forward: false,
+		},
+		{
+			name:     "pattern properties unchanged",
+			previous: `{"patternProperties": {"^x": {"type": "string"}}, "additionalProperties": false}`,
+			next:     `{"title": "x", "patternProperties": {"^x": {"type": "string"}}, "additionalProperties": false}`,
+			backward: true, forward: true,
+		},
+		{
+			name:     "property matching a pattern listed",
+			previous: `{"patternProperties": {"^x": {"type": "string"}}, "additionalProperties": false}`,
+			next:     `{"properties": {"xa": {"type": "string", "maxLength": 3}}, "patternProperties": {"^x": {"type": "string"}}, "additionalProperties": false}`,
+			backward: false, forward: true,
+		},
+		{
+			name:     "property matching a pattern unlisted",
+			previous: `{"properties": {"xa": {"type": "string", "maxLength": 3}}, "patternProperties": {"^x": {"type": "string"}}}`,
+			next:     `{"patternProperties": {"^x": {"type": "string"}}}`,
+			backward: true, forward: false,
+		},
+		{
+			name:     "ref with siblings changed",
+			previous: `{"definitions": {"id": {"type": "integer"}}, "properties": {"id": {"$ref": "#/definitions/id", "minimum": 1}}}`,
+			next:     `{"definitions": {"id": {"type": "integer"}}, "properties": {"id": {"minimum": 1}}}`,
+			backward: false, forward: false,
+		},
+		{
+			name:     "definitions changed",
+			previous: `{"definitions": {"id": {"type": "integer"}}, "properties": {"id": {"$ref": "#/definitions/id"}}}`,
+			next:     `{"definitions": {"id": {"type": "number"}}, "properties": {"id": {"$ref": "#/definitions/id"}}}`,
+			backward: false, forward: false,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := Check(c.previous, c.next, api.SchemaCompatibility_BACKWARD)
+			require.Equal(t, c.backward, err == nil, "backward: %v", err)
+			err = Check(c.previous, c.next, api.SchemaCompatibility_FORWARD)
+			require.Equal(t, c.forward, err == nil, "forward: %v", err)
+		})
+	}
+}
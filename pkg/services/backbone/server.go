@@ -0,0 +1,102 @@
+package backbone
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/contiamo/go-base/pkg/tracing"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/errmap"
+	"github.com/trusch/backbone-tools/pkg/services/events"
+	"github.com/trusch/backbone-tools/pkg/services/jobs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxOperations is the maximum number of operations of a single transaction
+const maxOperations = 1000
+
+// NewServer creates the server for operations spanning several services
+func NewServer(ctx context.Context, db *sql.DB, schema string) (api.BackboneServer, error) {
+	srv := &backboneServer{
+		Tracer: tracing.NewTracer("backbone", "BackboneServer"),
+		db:     db,
+		schema: schema,
+	}
+	return srv, nil
+}
+
+type backboneServer struct {
+	tracing.Tracer
+	db     *sql.DB
+	schema string
+}
+
+// Transact runs the operations with the regular service logic in one transaction.
+// Every operation is authorized like the corresponding service call, notifications are sent when the transaction commits.
+func (s *backboneServer) Transact(ctx context.Context, req *api.TransactRequest) (resp *api.TransactResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "Transact")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("operations", len(req.GetOperations()))
+	if len(req.GetOperations()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no operations")
+	}
+	if len(req.GetOperations()) > maxOperations {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d operations can be executed at once", maxOperations)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var (
+		jobsServer   = jobs.NewTxServer(tx, s.schema)
+		eventsServer = events.NewTxServer(tx, s.schema)
+	)
+	resp = &api.TransactResponse{}
+	for i, op := range req.GetOperations() {
+		result := &api.OperationResult{}
+		switch op := op.GetOperation().(type) {
+		case *api.Operation_CreateJob:
+			var job *api.Job
+			job, err = jobsServer.Create(ctx, op.CreateJob)
+			result.Result = &api.OperationResult_Job{Job: job}
+		case *api.Operation_DeleteJob:
+			var job *api.Job
+			job, err = jobsServer.Delete(ctx, op.DeleteJob)
+			result.Result = &api.OperationResult_Job{Job: job}
+		case *api.Operation_Heartbeat:
+			var job *api.Job
+			job, err = jobsServer.Heartbeat(ctx, op.Heartbeat)
+			result.Result = &api.OperationResult_Job{Job: job}
+		case *api.Operation_Publish:
+			var event *api.Event
+			event, err = eventsServer.Publish(ctx, op.Publish)
+			result.Result = &api.OperationResult_Event{Event: event}
+		default:
+			err = status.Error(codes.InvalidArgument, "unknown operation")
+		}
+		if err != nil {
+			return nil, operationError(i, err)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+// operationError prefixes the error with the index of the failed operation and keeps its status code
+func operationError(i int, err error) error {
+	st := status.Convert(errmap.ToStatus(err))
+	return status.Error(st.Code(), fmt.Sprintf("operation %d: %s", i, st.Message()))
+}
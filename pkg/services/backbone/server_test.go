@@ -0,0 +1,59 @@
+package backbone
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/internal/testdb"
+	"github.com/trusch/backbone-tools/pkg/sqlizers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTransact(t *testing.T) {
+	ctx := context.Background()
+	db, schema, cleanup := testdb.New(t, "backbone")
+	defer cleanup()
+	srv, err := NewServer(ctx, db, schema)
+	require.NoError(t, err)
+
+	count := func(table string) (n int) {
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+sqlizers.Table(schema, table)).Scan(&n))
+		return n
+	}
+
+	resp, err := srv.Transact(ctx, &api.TransactRequest{Operations: []*api.Operation{
+		{Operation: &api.Operation_CreateJob{CreateJob: &api.CreateJobRequest{Queue: "billing", Spec: []byte("{}")}}},
+		{Operation: &api.Operation_Publish{Publish: &api.PublishRequest{Topic: "orders.created"}}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 2)
+	job := resp.GetResults()[0].GetJob()
+	require.Equal(t, "billing", job.GetQueue())
+	require.Equal(t, "orders.created", resp.GetResults()[1].GetEvent().GetTopic())
+	require.Equal(t, 1, count("jobs"))
+	require.Equal(t, 1, count("events"))
+
+	// a failing operation rolls back the others
+	_, err = srv.Transact(ctx, &api.TransactRequest{Operations: []*api.Operation{
+		{Operation: &api.Operation_Heartbeat{Heartbeat: &api.HeartbeatRequest{JobId: job.GetId(), Finished: true}}},
+		{Operation: &api.Operation_Publish{Publish: &api.PublishRequest{Topic: "orders.created"}}},
+		{Operation: &api.Operation_DeleteJob{DeleteJob: &api.DeleteRequest{Id: "5c3e8d0a-5f0b-4c3a-9a55-9ad2f1a4c7b1"}}},
+	}})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Contains(t, err.Error(), "operation 2")
+	require.Equal(t, 1, count("events"))
+	var finished sql.NullTime
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT finished_at FROM `+sqlizers.Table(schema, "jobs")).Scan(&finished))
+	require.False(t, finished.Valid)
+
+	_, err = srv.Transact(ctx, &api.TransactRequest{Operations: []*api.Operation{
+		{Operation: &api.Operation_Heartbeat{Heartbeat: &api.HeartbeatRequest{JobId: job.GetId(), Finished: true}}},
+		{Operation: &api.Operation_DeleteJob{DeleteJob: &api.DeleteRequest{Id: job.GetId()}}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, 0, count("jobs"))
+}
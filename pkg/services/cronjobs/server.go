@@ -14,7 +14,13 @@ import (
 	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/errmap"
+	"github.com/trusch/backbone-tools/pkg/namespace"
 	"github.com/trusch/backbone-tools/pkg/sqlizers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -22,15 +28,12 @@ var (
 	heartbeatDeadline = 20 * time.Second
 )
 
-func NewServer(ctx context.Context, db *sql.DB, jobsServer api.JobsServer) (api.CronJobsServer, error) {
+func NewServer(ctx context.Context, db *sql.DB, jobsServer api.JobsServer, schema string) (api.CronJobsServer, error) {
 	srv := &cronjobsServer{
 		Tracer:     tracing.NewTracer("cronjobs", "CronJobsServer"),
 		db:         db,
 		jobsServer: jobsServer,
-	}
-	err := srv.init(ctx)
-	if err != nil {
-		return nil, err
+		schema:     schema,
 	}
 	go srv.backend(ctx)
 	return srv, nil
@@ -40,27 +43,12 @@ type cronjobsServer struct {
 	tracing.Tracer
 	db         squirrel.StdSqlCtx
 	jobsServer api.JobsServer
-}
-
-func (s *cronjobsServer) init(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `
-CREATE TABLE IF NOT EXISTS cronjobs(
-  cronjob_id UUID PRIMARY KEY,
-  queue TEXT NOT NULL,
-  name TEXT UNIQUE,
-  spec BYTEA,
-  cron TEXT,
-  labels JSONB NOT NULL DEFAULT '{}',
-  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-  next_run_at TIMESTAMPTZ NOT NULL DEFAULT now()
-);
-`)
-	return err
+	schema     string
 }
 
 func (s *cronjobsServer) backend(ctx context.Context) {
 	err := s.scheduleJobs(ctx)
-	if err != nil && err != sql.ErrNoRows {
+	if err != nil && err != sql.ErrNoRows && !errmap.IsSerializationFailure(err) {
 		logrus.Errorf("failed to initially schedule jobs: %v", err)
 	}
 	ticker := time.NewTicker(pollInterval)
@@ -71,7 +59,7 @@ func (s *cronjobsServer) backend(ctx context.Context) {
 			return
 		case <-ticker.C:
 			err := s.scheduleJobs(ctx)
-			if err != nil && err != sql.ErrNoRows {
+			if err != nil && err != sql.ErrNoRows && !errmap.IsSerializationFailure(err) {
 				logrus.Errorf("failed to schedule jobs: %v", err)
 			}
 		}
@@ -88,6 +76,17 @@ func (s *cronjobsServer) Create(ctx context.Context, req *api.CreateCronJobReque
 	span.SetTag("queue", req.GetQueue())
 	span.SetTag("spec", string(req.GetSpec()))
 	span.SetTag("labels", req.GetLabels())
+	if err := channels.ValidateName("queue", req.GetQueue()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "cronjobs:create", req.GetQueue()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
 
 	id := uuid.NewV4().String()
 	span.SetTag("cronjob_id", id)
@@ -104,11 +103,12 @@ func (s *cronjobsServer) Create(ctx context.Context, req *api.CreateCronJobReque
 
 	_, err = cron.ParseStandard(req.GetCron())
 	if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.InvalidArgument, "invalid cron expression %q: %v", req.GetCron(), err)
 	}
 
-	_, err = s.getBuilder(s.db).Insert("cronjobs").Columns(
+	_, err = s.getBuilder(s.db).Insert(s.table("cronjobs")).Columns(
 		"cronjob_id",
+		"namespace",
 		"queue",
 		"name",
 		"labels",
@@ -118,6 +118,7 @@ func (s *cronjobsServer) Create(ctx context.Context, req *api.CreateCronJobReque
 		"next_run_at",
 	).Values(
 		id,
+		ns,
 		req.GetQueue(),
 		req.GetName(),
 		dbtypes.JSONBlob(req.GetLabels()),
@@ -127,11 +128,21 @@ func (s *cronjobsServer) Create(ctx context.Context, req *api.CreateCronJobReque
 		now,
 	).ExecContext(ctx)
 	if err != nil {
+		if errmap.IsUniqueViolation(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "cronjob with name %q already exists", req.GetName())
+		}
+		return nil, err
+	}
+
+	// a concurrent scheduler run will pick up the new cronjob, so serialization failures can be ignored here
+	err = s.scheduleJobs(ctx)
+	if err != nil && !errmap.IsSerializationFailure(err) {
 		return nil, err
 	}
 
 	return &api.CronJob{
 		Id:        id,
+		Namespace: ns,
 		Queue:     req.GetQueue(),
 		Labels:    req.GetLabels(),
 		Name:      req.GetName(),
@@ -139,7 +150,7 @@ func (s *cronjobsServer) Create(ctx context.Context, req *api.CreateCronJobReque
 		Cron:      req.GetCron(),
 		CreatedAt: nowProto,
 		NextRunAt: nowProto,
-	}, s.scheduleJobs(ctx)
+	}, nil
 
 }
 
@@ -156,6 +167,14 @@ func (s *cronjobsServer) Get(ctx context.Context, req *api.GetRequest) (cronjob
 		createdAt time.Time
 		nextRunAt *time.Time
 	)
+	if req.GetId() == "" && req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "either id or name must be specified")
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cronjob.Namespace = ns
 	where := squirrel.Or{}
 	if id := req.GetId(); id != "" {
 		where = append(where, squirrel.Eq{"cronjob_id": id})
@@ -164,12 +183,18 @@ func (s *cronjobsServer) Get(ctx context.Context, req *api.GetRequest) (cronjob
 		where = append(where, squirrel.Eq{"name": name})
 	}
 	err = s.getBuilder(s.db).Select("cronjob_id", "name", "labels", "queue", "spec", "cron", "created_at", "next_run_at").
-		From("cronjobs").
-		Where(where).
+		From(s.table("cronjobs")).
+		Where(squirrel.And{squirrel.Eq{"namespace": ns}, where}).
 		QueryRowContext(ctx).Scan(&cronjob.Id, &cronjob.Name, dbtypes.JSONBlob(&cronjob.Labels), &cronjob.Queue, &cronjob.Spec, &cronjob.Cron, &createdAt, &nextRunAt)
+	if err == sql.ErrNoRows {
+		return nil, errCronJobNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
+	if err := authorizeCronJob(ctx, "cronjobs:get", cronjob); err != nil {
+		return nil, err
+	}
 	cronjob.CreatedAt, err = ptypes.TimestampProto(createdAt)
 	if err != nil {
 		return nil, err
@@ -209,14 +234,17 @@ func (s *cronjobsServer) Delete(ctx context.Context, req *api.DeleteRequest) (cr
 	}()
 
 	// get job
-	cronjob, err = (&cronjobsServer{s.Tracer, tx, s.jobsServer}).Get(ctx, &api.GetRequest{Id: req.GetId(), Name: req.GetName()})
+	cronjob, err = s.withTx(tx).Get(auth.NewSystemContext(ctx), &api.GetRequest{Id: req.GetId(), Name: req.GetName()})
 	if err != nil {
 		return nil, err
 	}
+	if err := authorizeCronJob(ctx, "cronjobs:delete", cronjob); err != nil {
+		return nil, err
+	}
 
 	_, err = s.getBuilder(tx).
-		Delete("cronjobs").
-		Where(squirrel.Eq{"cronjob_id": cronjob.GetId()}).
+		Delete(s.table("cronjobs")).
+		Where(squirrel.Eq{"cronjob_id": cronjob.GetId(), "namespace": cronjob.GetNamespace()}).
 		ExecContext(ctx)
 	if err != nil {
 		return nil, err
@@ -239,6 +267,9 @@ func (s *cronjobsServer) scheduleJobs(ctx context.Context) (err error) {
 	tx, err := rawDB.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelSerializable,
 	})
+	if err != nil {
+		return err
+	}
 	defer func() {
 		if err != nil {
 			tx.Rollback()
@@ -248,8 +279,8 @@ func (s *cronjobsServer) scheduleJobs(ctx context.Context) (err error) {
 
 	now := time.Now()
 
-	rows, err := s.getBuilder(tx).Select("cronjob_id", "name", "queue", "spec", "cron", "labels").
-		From("cronjobs").
+	rows, err := s.getBuilder(tx).Select("cronjob_id", "namespace", "name", "queue", "spec", "cron", "labels").
+		From(s.table("cronjobs")).
 		Where(squirrel.Lt{
 			"next_run_at": now,
 		}).
@@ -263,7 +294,7 @@ func (s *cronjobsServer) scheduleJobs(ctx context.Context) (err error) {
 		var (
 			cronjob api.CronJob
 		)
-		err = rows.Scan(&cronjob.Id, &cronjob.Name, &cronjob.Queue, &cronjob.Spec, &cronjob.Cron, dbtypes.JSONBlob(&cronjob.Labels))
+		err = rows.Scan(&cronjob.Id, &cronjob.Namespace, &cronjob.Name, &cronjob.Queue, &cronjob.Spec, &cronjob.Cron, dbtypes.JSONBlob(&cronjob.Labels))
 		if err != nil {
 			_ = rows.Close()
 			return err
@@ -281,7 +312,7 @@ func (s *cronjobsServer) scheduleJobs(ctx context.Context) (err error) {
 		logrus.Infof("next run is at %v", nextRunAt)
 
 		_, err = s.getBuilder(tx).
-			Update("cronjobs").
+			Update(s.table("cronjobs")).
 			Set("next_run_at", nextRunAt).
 			Where(squirrel.Eq{"cronjob_id": cronjob.GetId()}).
 			ExecContext(ctx)
@@ -303,7 +334,8 @@ func (s *cronjobsServer) scheduleJobs(ctx context.Context) (err error) {
 		labels["@system/cronjob-id"] = cronjob.GetId()
 		labels["@system/cronjob-name"] = cronjob.GetName()
 
-		createdJob, err := s.jobsServer.Create(ctx, &api.CreateJobRequest{
+		jobCtx := namespace.NewContext(auth.NewSystemContext(ctx), cronjob.GetNamespace())
+		createdJob, err := s.jobsServer.Create(jobCtx, &api.CreateJobRequest{
 			Queue:  cronjob.Queue,
 			Spec:   cronjob.Spec,
 			Labels: labels,
@@ -311,12 +343,33 @@ func (s *cronjobsServer) scheduleJobs(ctx context.Context) (err error) {
 		if err != nil {
 			logrus.Error(err)
 		} else {
-			logrus.Infof("scheduled new job %s in queue %s", createdJob.GetId(), createdJob.GetQueue())
+			logrus.Infof("scheduled new job %s in queue %s of namespace %s", createdJob.GetId(), createdJob.GetQueue(), createdJob.GetNamespace())
 		}
 	}
 	return nil
 }
 
+// errCronJobNotFound doesn't name the cronjob, requests may look it up by id or name
+var errCronJobNotFound = status.Error(codes.NotFound, "cronjob not found")
+
+// authorizeCronJob checks an action on the queue of a cronjob. Callers which may not access the queue
+// get the same error as for a missing cronjob, so they can't find out which cronjobs exist.
+func authorizeCronJob(ctx context.Context, action string, cronjob *api.CronJob) error {
+	err := auth.Authorize(ctx, cronjob.GetNamespace(), action, cronjob.GetQueue())
+	if status.Code(err) == codes.PermissionDenied {
+		return errCronJobNotFound
+	}
+	return err
+}
+
+func (s *cronjobsServer) table(name string) string {
+	return sqlizers.Table(s.schema, name)
+}
+
+func (s *cronjobsServer) withTx(tx *sql.Tx) *cronjobsServer {
+	return &cronjobsServer{s.Tracer, tx, s.jobsServer, s.schema}
+}
+
 func (s *cronjobsServer) getBuilder(db squirrel.BaseRunner) squirrel.StatementBuilderType {
 	return squirrel.StatementBuilder.
 		PlaceholderFormat(squirrel.Dollar).
@@ -334,7 +387,22 @@ func (s *cronjobsServer) List(req *api.ListRequest, resp api.CronJobs_ListServer
 	if req.Labels == nil {
 		req.Labels = make(map[string]string)
 	}
-	filter := squirrel.And{}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	span.SetTag("namespace", ns)
+	for _, queue := range req.GetQueues() {
+		if err := auth.Authorize(ctx, ns, "cronjobs:list", queue); err != nil {
+			return err
+		}
+	}
+	if len(req.GetQueues()) == 0 {
+		if err := auth.Authorize(ctx, ns, "cronjobs:list", "*"); err != nil {
+			return err
+		}
+	}
+	filter := squirrel.And{squirrel.Eq{"namespace": ns}}
 	if queues := req.GetQueues(); len(queues) > 0 {
 		filter = append(filter, squirrel.Eq{
 			"queue": req.GetQueues(),
@@ -347,7 +415,7 @@ func (s *cronjobsServer) List(req *api.ListRequest, resp api.CronJobs_ListServer
 	}
 	rows, err := s.getBuilder(s.db).
 		Select("cronjob_id", "name", "labels", "queue", "spec", "cron", "created_at", "next_run_at").
-		From("cronjobs").
+		From(s.table("cronjobs")).
 		Where(filter).
 		QueryContext(ctx)
 	if err != nil {
@@ -355,7 +423,7 @@ func (s *cronjobsServer) List(req *api.ListRequest, resp api.CronJobs_ListServer
 	}
 	for rows.Next() {
 		var (
-			cronjob   api.CronJob
+			cronjob   = api.CronJob{Namespace: ns}
 			createdAt time.Time
 			nextRunAt *time.Time
 		)
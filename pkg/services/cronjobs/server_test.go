@@ -0,0 +1,85 @@
+package cronjobs
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/errmap"
+	"github.com/trusch/backbone-tools/pkg/internal/testdb"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"github.com/trusch/backbone-tools/pkg/services/jobs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestServer(t *testing.T) (*cronjobsServer, *sql.DB, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	db, schema, cleanup := testdb.New(t, "cronjobs")
+	jobsSrv, err := jobs.NewServer(ctx, db, testdb.ConnectString(), schema)
+	require.NoError(t, err)
+	srv, err := NewServer(ctx, db, jobsSrv, schema)
+	require.NoError(t, err)
+	return srv.(*cronjobsServer), db, func() {
+		cancel()
+		cleanup()
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	srv, db, cleanup := newTestServer(t)
+	defer cleanup()
+	ctxA := namespace.NewContext(context.Background(), "team-a")
+	ctxB := namespace.NewContext(context.Background(), "team-b")
+
+	cronjob, err := srv.Create(ctxA, &api.CreateCronJobRequest{Name: "nightly", Queue: "reports", Cron: "0 3 * * *"})
+	require.NoError(t, err)
+
+	// other namespaces neither see nor delete the cronjob
+	_, err = srv.Get(ctxB, &api.GetRequest{Id: cronjob.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(errmap.ToStatus(err)))
+	_, err = srv.Get(ctxB, &api.GetRequest{Name: "nightly"})
+	require.Equal(t, codes.NotFound, status.Code(errmap.ToStatus(err)))
+	_, err = srv.Delete(ctxB, &api.DeleteRequest{Id: cronjob.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(errmap.ToStatus(err)))
+
+	// names are unique per namespace
+	_, err = srv.Create(ctxB, &api.CreateCronJobRequest{Name: "nightly", Queue: "reports", Cron: "0 3 * * *"})
+	require.NoError(t, err)
+
+	// the first run is scheduled in the namespace of the cronjob
+	var ns string
+	err = db.QueryRow(`SELECT namespace FROM `+srv.table("jobs")+` WHERE labels->>'@system/cronjob-id' = $1`, cronjob.GetId()).Scan(&ns)
+	require.NoError(t, err)
+	require.Equal(t, "team-a", ns)
+
+	got, err := srv.Get(ctxA, &api.GetRequest{Name: "nightly"})
+	require.NoError(t, err)
+	require.Equal(t, cronjob.GetId(), got.GetId())
+}
+
+func TestCreateErrors(t *testing.T) {
+	srv, _, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := srv.Create(ctx, &api.CreateCronJobRequest{Name: "nightly", Queue: "reports", Cron: "0 3 * * *"})
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		name string
+		req  *api.CreateCronJobRequest
+		code codes.Code
+	}{
+		{name: "duplicate name", req: &api.CreateCronJobRequest{Name: "nightly", Queue: "exports", Cron: "0 4 * * *"}, code: codes.AlreadyExists},
+		{name: "bad cron expression", req: &api.CreateCronJobRequest{Name: "hourly", Queue: "reports", Cron: "every now and then"}, code: codes.InvalidArgument},
+		{name: "bad queue", req: &api.CreateCronJobRequest{Name: "hourly", Queue: "", Cron: "0 * * * *"}, code: codes.InvalidArgument},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := srv.Create(ctx, c.req)
+			require.Equal(t, c.code, status.Code(errmap.ToStatus(err)))
+		})
+	}
+}
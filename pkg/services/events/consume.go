@@ -0,0 +1,151 @@
+package events
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/jackc/pgx/v4"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"github.com/trusch/backbone-tools/pkg/ticker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	defaultVisibilityTimeout        = 30 * time.Second
+	defaultMaxInFlight       uint32 = 100
+)
+
+// Consume delivers the events of a consumer group at least once.
+// Events are handed out like in Subscribe with a group, but they are only committed once the client acknowledged them.
+// Events which are not acknowledged within the visibility timeout, e.g. because the consumer crashed, are delivered again.
+func (s *eventsServer) Consume(stream api.Events_ConsumeServer) (err error) {
+	span, ctx := s.StartSpan(stream.Context(), "Consume")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	req := first.GetSubscribe()
+	if req == nil {
+		return status.Error(codes.InvalidArgument, "the first message must contain the subscription")
+	}
+	span.SetTag("topic", req.GetTopic())
+	span.SetTag("group", req.GetGroup())
+	if err := channels.ValidateTopicPattern(req.GetTopic()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := channels.ValidateName("group", req.GetGroup()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	visibility := defaultVisibilityTimeout
+	if first.GetVisibilityTimeout() != nil {
+		visibility, err = ptypes.Duration(first.GetVisibilityTimeout())
+		if err != nil || visibility <= 0 {
+			return status.Error(codes.InvalidArgument, "visibility timeout must be positive")
+		}
+	}
+	maxInFlight := defaultMaxInFlight
+	if first.GetMaxInFlight() > 0 {
+		maxInFlight = first.GetMaxInFlight()
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, ns, "events:subscribe", req.GetTopic()); err != nil {
+		return err
+	}
+	span.SetTag("namespace", ns)
+
+	notifyConn, err := pgx.Connect(ctx, s.connectString)
+	if err != nil {
+		return err
+	}
+	defer notifyConn.Close(context.Background())
+	ticker := ticker.New(pollInterval, 0.1, notifyConn, channels.EventsSubscription(s.schema, ns, req.GetTopic()))
+	if err := ticker.Start(ctx); err != nil {
+		return err
+	}
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	acks := make(chan []uint64)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			if len(msg.GetAcks()) == 0 {
+				continue
+			}
+			select {
+			case acks <- msg.GetAcks():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// inFlight holds the send times of the unacknowledged events of this stream
+	inFlight := make(map[uint64]time.Time)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-recvErr:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case seqs := <-acks:
+			if err := s.ackEvents(ctx, ns, req, seqs); err != nil {
+				return err
+			}
+			for _, seq := range seqs {
+				delete(inFlight, seq)
+			}
+		case <-ticker.C:
+		}
+
+		// expired events may be redelivered to other members of the group
+		for seq, sent := range inFlight {
+			if time.Since(sent) > visibility {
+				delete(inFlight, seq)
+			}
+		}
+		for uint32(len(inFlight)) < maxInFlight {
+			limit := uint64(maxInFlight) - uint64(len(inFlight))
+			if limit > groupBatchSize {
+				limit = groupBatchSize
+			}
+			events, err := s.claimEvents(ctx, ns, req, limit, visibility)
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				break
+			}
+			for _, event := range events {
+				span, _ := s.StartSpan(ctx, "sendEvent")
+				err = stream.Send(event)
+				s.FinishSpan(span, err)
+				if err != nil {
+					return err
+				}
+				inFlight[event.Sequence] = time.Now()
+			}
+		}
+	}
+}
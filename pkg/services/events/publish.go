@@ -0,0 +1,228 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"time"
+
+	dbtypes "github.com/contiamo/go-base/pkg/db/serialization"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxBatchSize is the maximum number of events published at once
+	maxBatchSize = 10000
+	// insertChunkSize keeps the number of parameters of a single insert below the postgres limit
+	insertChunkSize = 1000
+)
+
+func (s *eventsServer) PublishBatch(ctx context.Context, req *api.PublishBatchRequest) (resp *api.PublishBatchResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "PublishBatch")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("events", len(req.GetEvents()))
+	return s.publishBatch(ctx, req.GetEvents())
+}
+
+func (s *eventsServer) PublishStream(stream api.Events_PublishStreamServer) (err error) {
+	span, ctx := s.StartSpan(stream.Context(), "PublishStream")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	var reqs []*api.PublishRequest
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(reqs) == maxBatchSize {
+			return status.Errorf(codes.InvalidArgument, "at most %d events can be published at once", maxBatchSize)
+		}
+		reqs = append(reqs, req)
+	}
+	span.SetTag("events", len(reqs))
+	resp, err := s.publishBatch(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// publishBatch validates and authorizes all events before publishing them, the response omits payloads and labels
+func (s *eventsServer) publishBatch(ctx context.Context, reqs []*api.PublishRequest) (*api.PublishBatchResponse, error) {
+	if len(reqs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no events to publish")
+	}
+	if len(reqs) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d events can be published at once", maxBatchSize)
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authorized := make(map[string]bool)
+	for _, req := range reqs {
+		if authorized[req.GetTopic()] {
+			continue
+		}
+		if err := channels.ValidateName("topic", req.GetTopic()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := auth.Authorize(ctx, ns, "events:publish", req.GetTopic()); err != nil {
+			return nil, err
+		}
+		authorized[req.GetTopic()] = true
+	}
+
+	events, err := s.publishEvents(ctx, ns, reqs)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		event.Payload = nil
+		event.Labels = nil
+	}
+	return &api.PublishBatchResponse{Events: events}, nil
+}
+
+// publishEvents inserts validated events in one transaction and notifies the subscribers of every topic once.
+// The events are returned in request order.
+func (s *eventsServer) publishEvents(ctx context.Context, ns string, reqs []*api.PublishRequest) (events []*api.Event, err error) {
+	now := time.Now()
+	nowProto, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*api.Event, len(reqs))
+	counts := make(map[string]uint64)
+	for _, req := range reqs {
+		labels := req.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		event := &api.Event{
+			Id:        uuid.NewV4().String(),
+			Namespace: ns,
+			Topic:     req.GetTopic(),
+			Labels:    labels,
+			Payload:   req.GetPayload(),
+			CreatedAt: nowProto,
+		}
+		events = append(events, event)
+		byID[event.Id] = event
+		counts[event.Topic]++
+	}
+
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		// Sequences are taken from a global serial while holding the publish lock of the namespace until commit.
+		// Without it a publish which takes longer to commit becomes visible after events with higher sequences
+		// and subscribers resuming after the last seen sequence would skip it.
+		// This limits the publish throughput of a namespace to one transaction at a time, locking per topic
+		// isn't enough because sequences and pattern subscriptions span topics.
+		_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1), hashtext($2))`, s.schema, ns)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock namespace")
+		}
+		if err := s.validatePayloads(ctx, tx, ns, events); err != nil {
+			return err
+		}
+
+		// allocate the topic sequences of all events of a topic at once
+		next := make(map[string]uint64, len(counts))
+		for topic, n := range counts {
+			var last uint64
+			err = s.getBuilder(tx).Insert(s.table("topic_sequences")).
+				Columns("namespace", "topic", "last_sequence").
+				Values(ns, topic, n).
+				Suffix("ON CONFLICT (namespace, topic) DO UPDATE SET last_sequence = topic_sequences.last_sequence + EXCLUDED.last_sequence RETURNING last_sequence").
+				QueryRowContext(ctx).Scan(&last)
+			if err != nil {
+				return errors.Wrap(err, "failed to allocate topic sequences")
+			}
+			next[topic] = last - n + 1
+		}
+		for _, event := range events {
+			event.TopicSequence = next[event.Topic]
+			next[event.Topic]++
+		}
+
+		for start := 0; start < len(events); start += insertChunkSize {
+			end := start + insertChunkSize
+			if end > len(events) {
+				end = len(events)
+			}
+			if err := s.insertEvents(ctx, tx, events[start:end], now, byID); err != nil {
+				return err
+			}
+		}
+
+		// notifications are delivered on commit
+		topics := make([]string, 0, len(counts))
+		for topic := range counts {
+			topics = append(topics, topic)
+		}
+		for _, channel := range channels.EventsPublished(s.schema, ns, topics...) {
+			if err := channels.Notify(ctx, tx, channel); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// insertEvents inserts the events with a single statement and sets their sequences
+func (s *eventsServer) insertEvents(ctx context.Context, tx *sql.Tx, events []*api.Event, createdAt time.Time, byID map[string]*api.Event) error {
+	builder := s.getBuilder(tx).Insert(s.table("events")).Columns(
+		"event_id",
+		"namespace",
+		"topic",
+		"labels",
+		"payload",
+		"created_at",
+		"topic_sequence",
+	)
+	for _, event := range events {
+		builder = builder.Values(
+			event.Id,
+			event.Namespace,
+			event.Topic,
+			dbtypes.JSONBlob(event.Labels),
+			event.Payload,
+			createdAt,
+			event.TopicSequence,
+		)
+	}
+	rows, err := builder.Suffix("RETURNING event_id, \"sequence\"").QueryContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert events")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id  string
+			seq uint64
+		)
+		if err := rows.Scan(&id, &seq); err != nil {
+			return err
+		}
+		byID[id].Sequence = seq
+	}
+	return rows.Err()
+}
@@ -0,0 +1,167 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// replayedTopicLabel and replayedSequenceLabel point from a replayed event to its original
+	replayedTopicLabel    = "@system/replayed-topic"
+	replayedSequenceLabel = "@system/replayed-sequence"
+)
+
+// maxReplaySize is the maximum number of events copied by one replay, like a batch they are published in one transaction
+var maxReplaySize = maxBatchSize
+
+func (s *eventsServer) Export(req *api.ExportRequest, resp api.Events_ExportServer) (err error) {
+	span, ctx := s.StartSpan(resp.Context(), "Export")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("topic", req.GetTopic())
+	if err := channels.ValidateTopicPattern(req.GetTopic()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, ns, "events:subscribe", req.GetTopic()); err != nil {
+		return err
+	}
+	span.SetTag("namespace", ns)
+
+	filter, err := windowFilter(ns, req.GetTopic(), req.GetLabels(), 0, req.GetFrom(), req.GetTo())
+	if err != nil {
+		return err
+	}
+	rows, err := s.getBuilder(s.db).
+		Select(eventColumns...).
+		From(s.table("events")).
+		Where(filter).
+		OrderBy("sequence ASC").
+		QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		event, err := scanEvent(rows, ns)
+		if err != nil {
+			return err
+		}
+		if err := resp.Send(event); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Replay copies the events of a time window to the target topic. The copies get new ids, sequences and creation times
+// and point to their originals with the labels @system/replayed-topic and @system/replayed-sequence.
+// Windows with more than maxReplaySize events are rejected.
+func (s *eventsServer) Replay(ctx context.Context, req *api.ReplayRequest) (resp *api.ReplayResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "Replay")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("topic", req.GetTopic())
+	span.SetTag("target_topic", req.GetTargetTopic())
+	if err := channels.ValidateTopicPattern(req.GetTopic()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := channels.ValidateName("target topic", req.GetTargetTopic()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if channels.MatchTopic(req.GetTopic(), req.GetTargetTopic()) {
+		return nil, status.Error(codes.InvalidArgument, "the target topic must not be one of the replayed topics")
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "events:subscribe", req.GetTopic()); err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "events:publish", req.GetTargetTopic()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+
+	// the window is read before publishing, so the publish lock of the namespace is only held while the copies are inserted
+	filter, err := windowFilter(ns, req.GetTopic(), req.GetLabels(), 0, req.GetFrom(), req.GetTo())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.getBuilder(s.db).
+		Select(eventColumns...).
+		From(s.table("events")).
+		Where(filter).
+		OrderBy("sequence ASC").
+		Limit(uint64(maxReplaySize) + 1).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events, err := scanEvents(rows, ns)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > maxReplaySize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d events can be replayed at once, narrow the time window", maxReplaySize)
+	}
+	if len(events) == 0 {
+		return &api.ReplayResponse{}, nil
+	}
+
+	reqs := make([]*api.PublishRequest, len(events))
+	for i, event := range events {
+		labels := make(map[string]string, len(event.Labels)+2)
+		for k, v := range event.Labels {
+			labels[k] = v
+		}
+		labels[replayedTopicLabel] = event.Topic
+		labels[replayedSequenceLabel] = fmt.Sprint(event.Sequence)
+		reqs[i] = &api.PublishRequest{
+			Topic:   req.GetTargetTopic(),
+			Labels:  labels,
+			Payload: event.Payload,
+		}
+	}
+	copies, err := s.publishEvents(ctx, ns, reqs)
+	if err != nil {
+		return nil, err
+	}
+	return &api.ReplayResponse{
+		Events:        uint64(len(copies)),
+		FirstSequence: copies[0].Sequence,
+		LastSequence:  copies[len(copies)-1].Sequence,
+	}, nil
+}
+
+// windowFilter selects the events of a topic or pattern created in [from, to) after the given sequence
+func windowFilter(ns, topic string, labels map[string]string, afterSequence uint64, from, to *timestamp.Timestamp) (squirrel.And, error) {
+	filter, err := eventFilter(ns, topic, labels, afterSequence, from)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if to != nil {
+		ts, err := ptypes.Timestamp(to)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter = append(filter, squirrel.Lt{"created_at": ts})
+	}
+	return filter, nil
+}
@@ -0,0 +1,299 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/contiamo/go-base/pkg/tracing"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"github.com/trusch/backbone-tools/pkg/schemas"
+	"github.com/trusch/backbone-tools/pkg/sqlizers"
+	"github.com/xeipuuv/gojsonschema"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewSchemasServer creates the registry of JSON schemas for event payloads.
+// Once a topic has a schema, the events server rejects payloads which don't match its latest version.
+func NewSchemasServer(ctx context.Context, db *sql.DB, schema string) (api.SchemasServer, error) {
+	srv := &schemasServer{
+		Tracer: tracing.NewTracer("events", "SchemasServer"),
+		db:     db,
+		schema: schema,
+	}
+	return srv, nil
+}
+
+type schemasServer struct {
+	tracing.Tracer
+	db     *sql.DB
+	schema string
+}
+
+func (s *schemasServer) table(name string) string {
+	return sqlizers.Table(s.schema, name)
+}
+
+func (s *schemasServer) getBuilder(db squirrel.BaseRunner) squirrel.StatementBuilderType {
+	return squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		RunWith(db)
+}
+
+// Register adds a new version of the schema of a topic after checking its compatibility to the latest version.
+// Registering the latest schema again returns the latest version.
+func (s *schemasServer) Register(ctx context.Context, req *api.RegisterSchemaRequest) (res *api.EventSchema, err error) {
+	span, ctx := s.StartSpan(ctx, "Register")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("topic", req.GetTopic())
+	if err := channels.ValidateName("topic", req.GetTopic()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "schemas:register", req.GetTopic()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	if _, err := schemas.Compile(req.GetSchema()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid schema: %v", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	// registrations of a topic queue up behind each other
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1), hashtext($2))`, s.schema+"\x00schemas", ns+"\x00"+req.GetTopic())
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := getSchema(ctx, s.getBuilder(tx), s.table("event_schemas"), ns, req.GetTopic(), 0)
+	switch {
+	case err == sql.ErrNoRows:
+		latest = &api.EventSchema{}
+	case err != nil:
+		return nil, err
+	case latest.GetSchema() == req.GetSchema():
+		return latest, nil
+	default:
+		if err := schemas.Check(latest.GetSchema(), req.GetSchema(), req.GetCompatibility()); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "schema is not %s compatible to version %d: %v",
+				strings.ToLower(req.GetCompatibility().String()), latest.GetVersion(), err)
+		}
+	}
+
+	now := time.Now()
+	res = &api.EventSchema{
+		Namespace:     ns,
+		Topic:         req.GetTopic(),
+		Version:       latest.GetVersion() + 1,
+		Schema:        req.GetSchema(),
+		Compatibility: req.GetCompatibility(),
+	}
+	res.CreatedAt, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.getBuilder(tx).Insert(s.table("event_schemas")).
+		Columns("namespace", "topic", "version", "schema", "compatibility", "created_at").
+		Values(ns, res.Topic, res.Version, res.Schema, res.Compatibility.String(), now).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *schemasServer) Get(ctx context.Context, req *api.GetSchemaRequest) (res *api.EventSchema, err error) {
+	span, ctx := s.StartSpan(ctx, "Get")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("topic", req.GetTopic())
+	span.SetTag("version", req.GetVersion())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "schemas:get", req.GetTopic()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	res, err = getSchema(ctx, s.getBuilder(s.db), s.table("event_schemas"), ns, req.GetTopic(), req.GetVersion())
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "no schema for topic %s", req.GetTopic())
+	}
+	return res, err
+}
+
+func (s *schemasServer) List(req *api.ListSchemasRequest, resp api.Schemas_ListServer) (err error) {
+	span, ctx := s.StartSpan(resp.Context(), "List")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("prefix", req.GetPrefix())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, ns, "schemas:list", req.GetPrefix()+"*"); err != nil {
+		return err
+	}
+	span.SetTag("namespace", ns)
+
+	rows, err := s.getBuilder(s.db).Select(schemaColumns...).
+		From(s.table("event_schemas")).
+		Where(squirrel.Eq{"namespace": ns}).
+		Where(squirrel.Like{"topic": sqlizers.LikePrefix(req.GetPrefix())}).
+		OrderBy("topic ASC", "version ASC").
+		QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		res, err := scanSchema(rows, ns)
+		if err != nil {
+			return err
+		}
+		if err := resp.Send(res); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// schemaColumns are the columns read by scanSchema
+var schemaColumns = []string{"topic", "version", "schema", "compatibility", "created_at"}
+
+// getSchema returns a version of the schema of a topic or the latest version if version is 0
+func getSchema(ctx context.Context, builder squirrel.StatementBuilderType, table, ns, topic string, version uint32) (*api.EventSchema, error) {
+	query := builder.Select(schemaColumns...).
+		From(table).
+		Where(squirrel.Eq{"namespace": ns, "topic": topic}).
+		OrderBy("version DESC").
+		Limit(1)
+	if version > 0 {
+		query = query.Where(squirrel.Eq{"version": version})
+	}
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
+	}
+	return scanSchema(rows, ns)
+}
+
+func scanSchema(rows *sql.Rows, ns string) (*api.EventSchema, error) {
+	var (
+		res           = &api.EventSchema{Namespace: ns}
+		compatibility string
+		createdAt     time.Time
+	)
+	if err := rows.Scan(&res.Topic, &res.Version, &res.Schema, &compatibility, &createdAt); err != nil {
+		return nil, err
+	}
+	res.Compatibility = api.SchemaCompatibility(api.SchemaCompatibility_value[compatibility])
+	var err error
+	res.CreatedAt, err = ptypes.TimestampProto(createdAt)
+	return res, err
+}
+
+// maxCachedSchemas bounds the number of compiled schemas kept in memory
+const maxCachedSchemas = 1024
+
+// compiledSchemas caches the compiled latest schema of topics by schema, namespace and topic
+var compiledSchemas = struct {
+	sync.Mutex
+	entries map[string]compiledSchema
+}{entries: make(map[string]compiledSchema)}
+
+type compiledSchema struct {
+	version uint32
+	schema  *gojsonschema.Schema
+}
+
+// validatePayloads checks the payloads of events against the latest schemas of their topics
+func (s *eventsServer) validatePayloads(ctx context.Context, tx *sql.Tx, ns string, events []*api.Event) error {
+	compiled := make(map[string]*gojsonschema.Schema)
+	for i, event := range events {
+		schema, ok := compiled[event.Topic]
+		if !ok {
+			latest, err := getSchema(ctx, s.getBuilder(tx), s.table("event_schemas"), ns, event.Topic, 0)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			if latest != nil {
+				schema, err = s.compileSchema(ns, latest)
+				if err != nil {
+					return err
+				}
+			}
+			compiled[event.Topic] = schema
+		}
+		if schema == nil {
+			continue
+		}
+		if err := schemas.Validate(schema, event.Payload); err != nil {
+			msg := fmt.Sprintf("payload does not match the schema of topic %s: %v", event.Topic, err)
+			if len(events) > 1 {
+				msg = fmt.Sprintf("event %d: %s", i, msg)
+			}
+			return status.Error(codes.InvalidArgument, msg)
+		}
+	}
+	return nil
+}
+
+func (s *eventsServer) compileSchema(ns string, es *api.EventSchema) (*gojsonschema.Schema, error) {
+	key := fmt.Sprintf("%s\x00%s\x00%s", s.schema, ns, es.GetTopic())
+	compiledSchemas.Lock()
+	cached, ok := compiledSchemas.entries[key]
+	compiledSchemas.Unlock()
+	if ok && cached.version == es.GetVersion() {
+		return cached.schema, nil
+	}
+	schema, err := schemas.Compile(es.GetSchema())
+	if err != nil {
+		return nil, err
+	}
+	compiledSchemas.Lock()
+	defer compiledSchemas.Unlock()
+	if _, ok := compiledSchemas.entries[key]; !ok && len(compiledSchemas.entries) >= maxCachedSchemas {
+		// evict an arbitrary entry, it is compiled again when needed
+		for k := range compiledSchemas.entries {
+			delete(compiledSchemas.entries, k)
+			break
+		}
+	}
+	compiledSchemas.entries[key] = compiledSchema{es.GetVersion(), schema}
+	return schema, nil
+}
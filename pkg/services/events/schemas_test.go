@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/internal/fakes"
+	"github.com/trusch/backbone-tools/pkg/schemas"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSchemaRegistry(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+	registry, err := NewSchemasServer(ctx, srv.db.(*sql.DB), srv.schema)
+	require.NoError(t, err)
+
+	// topics without schema accept anything
+	_, err = srv.Publish(ctx, &api.PublishRequest{Topic: "orders", Payload: []byte("not json")})
+	require.NoError(t, err)
+
+	v1, err := registry.Register(ctx, &api.RegisterSchemaRequest{
+		Topic:  "orders",
+		Schema: `{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), v1.GetVersion())
+
+	_, err = srv.Publish(ctx, &api.PublishRequest{Topic: "orders", Payload: []byte(`{"id": 1}`)})
+	require.NoError(t, err)
+	_, err = srv.Publish(ctx, &api.PublishRequest{Topic: "orders", Payload: []byte(`{"id": "1"}`)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = srv.PublishBatch(ctx, &api.PublishBatchRequest{Events: []*api.PublishRequest{
+		{Topic: "orders", Payload: []byte(`{"id": 2}`)},
+		{Topic: "orders", Payload: []byte(`{}`)},
+	}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Contains(t, err.Error(), "event 1")
+
+	// requiring a new property rejects old payloads
+	_, err = registry.Register(ctx, &api.RegisterSchemaRequest{
+		Topic:  "orders",
+		Schema: `{"type": "object", "properties": {"id": {"type": "integer"}, "total": {"type": "number"}}, "required": ["id", "total"]}`,
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	next := `{"type": "object", "properties": {"id": {"type": "number"}}, "required": ["id"]}`
+	v2, err := registry.Register(ctx, &api.RegisterSchemaRequest{Topic: "orders", Schema: next})
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), v2.GetVersion())
+	again, err := registry.Register(ctx, &api.RegisterSchemaRequest{Topic: "orders", Schema: next})
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), again.GetVersion())
+	_, err = srv.Publish(ctx, &api.PublishRequest{Topic: "orders", Payload: []byte(`{"id": 1.5}`)})
+	require.NoError(t, err)
+
+	// without compatibility checks anything goes
+	v3, err := registry.Register(ctx, &api.RegisterSchemaRequest{
+		Topic:         "orders",
+		Schema:        `{"type": "string"}`,
+		Compatibility: api.SchemaCompatibility_NONE,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), v3.GetVersion())
+
+	got, err := registry.Get(ctx, &api.GetSchemaRequest{Topic: "orders", Version: 1})
+	require.NoError(t, err)
+	require.Equal(t, v1.GetSchema(), got.GetSchema())
+	got, err = registry.Get(ctx, &api.GetSchemaRequest{Topic: "orders"})
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), got.GetVersion())
+	require.Equal(t, api.SchemaCompatibility_NONE, got.GetCompatibility())
+	_, err = registry.Get(ctx, &api.GetSchemaRequest{Topic: "invoices"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	stream := &fakes.ListSchemasStream{}
+	require.NoError(t, registry.List(&api.ListSchemasRequest{Prefix: "ord"}, stream))
+	require.Len(t, stream.Schemas, 3)
+}
+
+func TestCompiledSchemasAreBounded(t *testing.T) {
+	srv := &eventsServer{schema: "cache_test"}
+	for i := 0; i < maxCachedSchemas+10; i++ {
+		_, err := srv.compileSchema("default", &api.EventSchema{Topic: fmt.Sprintf("t%d", i), Version: 1, Schema: `{"type": "object"}`})
+		require.NoError(t, err)
+	}
+	compiledSchemas.Lock()
+	require.Len(t, compiledSchemas.entries, maxCachedSchemas)
+	compiledSchemas.Unlock()
+
+	// a new version replaces the cached one
+	first, err := srv.compileSchema("default", &api.EventSchema{Topic: "orders", Version: 1, Schema: `{"type": "object"}`})
+	require.NoError(t, err)
+	second, err := srv.compileSchema("default", &api.EventSchema{Topic: "orders", Version: 2, Schema: `{"type": "string"}`})
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+	require.NoError(t, schemas.Validate(second, []byte(`"paid"`)))
+}
@@ -3,53 +3,57 @@ package events
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
 	dbtypes "github.com/contiamo/go-base/pkg/db/serialization"
 	"github.com/contiamo/go-base/pkg/tracing"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
-	uuid "github.com/satori/go.uuid"
 	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/namespace"
 	"github.com/trusch/backbone-tools/pkg/sqlizers"
 	"github.com/trusch/backbone-tools/pkg/ticker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
 	pollInterval = 10 * time.Second
+	// groupBatchSize is the number of events a consumer group member claims at once
+	groupBatchSize uint64 = 10
 )
 
-func NewServer(ctx context.Context, db *sql.DB, connectString string) (api.EventsServer, error) {
+func NewServer(ctx context.Context, db *sql.DB, connectString, schema string) (api.EventsServer, error) {
 	srv := &eventsServer{
 		Tracer:        tracing.NewTracer("events", "EventsServer"),
 		db:            db,
 		connectString: connectString,
+		schema:        schema,
 	}
-	return srv, srv.init(ctx)
+	return srv, nil
+}
+
+// NewTxServer creates an events server running all statements in tx, so events can be published atomically with other data.
+// Subscribers are notified when tx commits, subscriptions are not supported.
+// Publishing locks the namespace until tx ends, other publishes to the namespace wait for it.
+func NewTxServer(tx *sql.Tx, schema string) api.EventsServer {
+	return &eventsServer{tracing.NewTracer("events", "EventsServer"), tx, "", schema}
 }
 
 type eventsServer struct {
 	tracing.Tracer
 	db            squirrel.StdSqlCtx
 	connectString string
+	schema        string
 }
 
-func (s *eventsServer) init(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `
-CREATE TABLE IF NOT EXISTS events(
-  event_id UUID PRIMARY KEY,
-  topic TEXT NOT NULL,
-  payload BYTEA,
-  labels JSONB NOT NULL DEFAULT '{}',
-  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-  sequence SERIAL
-);
-`)
-	return err
+func (s *eventsServer) table(name string) string {
+	return sqlizers.Table(s.schema, name)
 }
 
 func (s *eventsServer) getBuilder(db squirrel.BaseRunner) squirrel.StatementBuilderType {
@@ -67,54 +71,23 @@ func (s *eventsServer) Publish(ctx context.Context, req *api.PublishRequest) (ev
 	span.SetTag("topic", req.GetTopic())
 	span.SetTag("labels", req.GetLabels())
 	span.SetTag("payload", req.GetPayload())
-
-	id := uuid.NewV4().String()
-	now := time.Now()
-	nowProto, err := ptypes.TimestampProto(now)
+	if err := channels.ValidateName("topic", req.GetTopic()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ns, err := namespace.FromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	if req.Labels == nil {
-		req.Labels = make(map[string]string)
-	}
-
-	builder := s.getBuilder(s.db).Insert("events").Columns(
-		"event_id",
-		"topic",
-		"labels",
-		"payload",
-		"created_at",
-	).Values(
-		id,
-		req.GetTopic(),
-		dbtypes.JSONBlob(req.GetLabels()),
-		req.GetPayload(),
-		now,
-	).Suffix("RETURNING \"sequence\"")
-
-	fmt.Println(builder.ToSql())
-
-	row := builder.QueryRowContext(ctx)
-	var seq uint64
-	if err := row.Scan(&seq); err != nil {
-		return nil, errors.Wrap(err, "failed to insert event")
+	if err := auth.Authorize(ctx, ns, "events:publish", req.GetTopic()); err != nil {
+		return nil, err
 	}
+	span.SetTag("namespace", ns)
 
-	_, err = s.db.ExecContext(ctx, `NOTIFY events_`+strings.Replace(req.GetTopic(), "-", "_", -1))
+	events, err := s.publishEvents(ctx, ns, []*api.PublishRequest{req})
 	if err != nil {
 		return nil, err
 	}
-
-	return &api.Event{
-		Id:        id,
-		Topic:     req.GetTopic(),
-		Labels:    req.GetLabels(),
-		Payload:   req.GetPayload(),
-		CreatedAt: nowProto,
-		Sequence:  seq,
-	}, nil
-
+	return events[0], nil
 }
 
 func (s *eventsServer) Subscribe(req *api.SubscribeRequest, resp api.Events_SubscribeServer) (err error) {
@@ -122,15 +95,35 @@ func (s *eventsServer) Subscribe(req *api.SubscribeRequest, resp api.Events_Subs
 	defer func() {
 		s.FinishSpan(span, err)
 	}()
+	span.SetTag("topic", req.GetTopic())
+	if err := channels.ValidateTopicPattern(req.GetTopic()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.GetGroup() != "" {
+		span.SetTag("group", req.GetGroup())
+		if err := channels.ValidateName("group", req.GetGroup()); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, ns, "events:subscribe", req.GetTopic()); err != nil {
+		return err
+	}
+	span.SetTag("namespace", ns)
 
 	notifyConn, err := pgx.Connect(ctx, s.connectString)
 	if err != nil {
 		return err
 	}
-	ticker := ticker.New(pollInterval, 0.1, notifyConn, "events_"+strings.Replace(req.GetTopic(), "-", "_", -1))
+	defer notifyConn.Close(context.Background())
+	ticker := ticker.New(pollInterval, 0.1, notifyConn, channels.EventsSubscription(s.schema, ns, req.GetTopic()))
 	if err := ticker.Start(ctx); err != nil {
 		return err
 	}
+	defer ticker.Stop()
 	lastSequence := req.GetSinceSequence()
 	timestamp := req.GetSinceCreatedAt()
 	for {
@@ -138,62 +131,385 @@ func (s *eventsServer) Subscribe(req *api.SubscribeRequest, resp api.Events_Subs
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			filter := squirrel.And{
-				squirrel.Eq{
-					"topic": req.GetTopic(),
-				},
-			}
-			if lastSequence > 0 {
-				filter = append(filter, squirrel.Gt{
-					"sequence": lastSequence,
-				})
-			}
-			if labels := req.GetLabels(); labels != nil {
-				filter = append(filter, sqlizers.JSONContains{
-					"labels": dbtypes.JSONBlob(labels),
-				})
-			}
-			if timestamp != nil {
-				ts, err := ptypes.Timestamp(timestamp)
-				if err != nil {
+			if req.GetGroup() != "" {
+				if err := s.sendGroupEvents(ctx, ns, req, resp); err != nil {
 					return err
 				}
-				filter = append(filter, squirrel.GtOrEq{
-					"created_at": ts,
-				})
+				break
 			}
 
+			filter, err := eventFilter(ns, req.GetTopic(), req.GetLabels(), lastSequence, timestamp)
+			if err != nil {
+				return err
+			}
 			rows, err := s.getBuilder(s.db).
-				Select("event_id", "labels", "payload", "created_at", "sequence").
-				From("events").
+				Select(eventColumns...).
+				From(s.table("events")).
 				Where(filter).
+				OrderBy("sequence ASC").
 				QueryContext(ctx)
 			if err != nil {
 				return err
 			}
 
 			for rows.Next() {
-				var (
-					event     = api.Event{Topic: req.GetTopic()}
-					createdAt time.Time
-				)
-				err = rows.Scan(&event.Id, dbtypes.JSONBlob(&event.Labels), &event.Payload, &createdAt, &event.Sequence)
-				if err != nil {
-					return err
-				}
-				event.CreatedAt, err = ptypes.TimestampProto(createdAt)
+				event, err := scanEvent(rows, ns)
 				if err != nil {
+					rows.Close()
 					return err
 				}
 				span, _ := s.StartSpan(ctx, "sendEvent")
-				err = resp.Send(&event)
+				err = resp.Send(event)
 				lastSequence = event.Sequence
 				timestamp = nil
 				s.FinishSpan(span, err)
 				if err != nil {
+					rows.Close()
 					return err
 				}
 			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendGroupEvents claims batches of events for a consumer group and sends them until no events are left.
+// Claiming commits the events, so the delivery is at-most-once.
+func (s *eventsServer) sendGroupEvents(ctx context.Context, ns string, req *api.SubscribeRequest, resp api.Events_SubscribeServer) error {
+	for {
+		events, err := s.claimEvents(ctx, ns, req, groupBatchSize, 0)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		for _, event := range events {
+			span, _ := s.StartSpan(ctx, "sendEvent")
+			err = resp.Send(event)
+			s.FinishSpan(span, err)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// claimEvents hands up to limit events of a consumer group to the caller. The group row is locked while claiming,
+// so every event goes to one subscriber of the group only.
+// With a visibility timeout the events are recorded as deliveries and handed out again if they are not acknowledged
+// before the timeout, expired deliveries are claimed before new events. Without it the events count as acknowledged right away.
+func (s *eventsServer) claimEvents(ctx context.Context, ns string, req *api.SubscribeRequest, limit uint64, visibility time.Duration) (events []*api.Event, err error) {
+	span, ctx := s.StartSpan(ctx, "claimEvents")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	err = s.groupTx(ctx, ns, req, func(tx *sql.Tx, group squirrel.Eq, claimed uint64) error {
+		now := time.Now()
+		if visibility > 0 {
+			events, err = s.redeliverEvents(ctx, tx, ns, req, group, limit, now.Add(visibility))
+			if err != nil {
+				return err
+			}
+			limit -= uint64(len(events))
+		}
+		if limit == 0 {
+			return nil
+		}
+
+		filter, err := eventFilter(ns, req.GetTopic(), req.GetLabels(), claimed, nil)
+		if err != nil {
+			return err
+		}
+		rows, err := s.getBuilder(tx).
+			Select(eventColumns...).
+			From(s.table("events")).
+			Where(filter).
+			OrderBy("sequence ASC").
+			Limit(limit).
+			QueryContext(ctx)
+		if err != nil {
+			return err
+		}
+		fresh, err := scanEvents(rows, ns)
+		if err != nil {
+			return err
+		}
+		if len(fresh) == 0 {
+			return nil
+		}
+		if visibility > 0 {
+			insert := s.getBuilder(tx).Insert(s.table("event_deliveries")).
+				Columns("namespace", "group_name", "topic", "sequence", "delivered_at", "visible_at")
+			for _, event := range fresh {
+				insert = insert.Values(ns, req.GetGroup(), req.GetTopic(), event.Sequence, now, now.Add(visibility))
+			}
+			if _, err := insert.ExecContext(ctx); err != nil {
+				return err
+			}
+		}
+		events = append(events, fresh...)
+		return s.commitGroup(ctx, tx, group, fresh[len(fresh)-1].Sequence)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// redeliverEvents claims up to limit deliveries of a consumer group whose visibility timeout passed and hides them until visibleAt
+func (s *eventsServer) redeliverEvents(ctx context.Context, tx *sql.Tx, ns string, req *api.SubscribeRequest, group squirrel.Eq, limit uint64, visibleAt time.Time) ([]*api.Event, error) {
+	rows, err := s.getBuilder(tx).Select("sequence").
+		From(s.table("event_deliveries")).
+		Where(group).
+		Where(squirrel.LtOrEq{"visible_at": time.Now()}).
+		OrderBy("sequence ASC").
+		Limit(limit).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var seqs []uint64
+	for rows.Next() {
+		var seq uint64
+		if err := rows.Scan(&seq); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		seqs = append(seqs, seq)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(seqs) == 0 {
+		return nil, nil
+	}
+
+	_, err = s.getBuilder(tx).Update(s.table("event_deliveries")).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("delivered_at", time.Now()).
+		Set("visible_at", visibleAt).
+		Where(group).
+		Where(squirrel.Eq{"sequence": seqs}).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err = s.getBuilder(tx).
+		Select(eventColumns...).
+		From(s.table("events")).
+		Where(squirrel.Eq{
+			"namespace": ns,
+			"sequence":  seqs,
+		}).
+		OrderBy("sequence ASC").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows, ns)
+}
+
+// ackEvents removes the deliveries of the given sequences and moves the committed sequence of the group forward
+func (s *eventsServer) ackEvents(ctx context.Context, ns string, req *api.SubscribeRequest, seqs []uint64) (err error) {
+	span, ctx := s.StartSpan(ctx, "ackEvents")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("acks", seqs)
+	return s.groupTx(ctx, ns, req, func(tx *sql.Tx, group squirrel.Eq, claimed uint64) error {
+		_, err := s.getBuilder(tx).Delete(s.table("event_deliveries")).
+			Where(group).
+			Where(squirrel.Eq{"sequence": seqs}).
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		return s.commitGroup(ctx, tx, group, claimed)
+	})
+}
+
+// groupTx runs fn in a transaction holding the lock on the consumer group, the group is created if it doesn't exist yet.
+// fn gets the sequence up to which events have been claimed by the group.
+func (s *eventsServer) groupTx(ctx context.Context, ns string, req *api.SubscribeRequest, fn func(tx *sql.Tx, group squirrel.Eq, claimed uint64) error) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		group := squirrel.Eq{
+			"namespace":  ns,
+			"group_name": req.GetGroup(),
+			"topic":      req.GetTopic(),
+		}
+		claimed, err := s.lockGroup(ctx, tx, group)
+		if err == sql.ErrNoRows {
+			var start uint64
+			start, err = s.initialSequence(ctx, tx, ns, req)
+			if err != nil {
+				return err
+			}
+			_, err = s.getBuilder(tx).Insert(s.table("consumer_groups")).
+				Columns("namespace", "group_name", "topic", "committed_sequence", "claimed_sequence").
+				Values(ns, req.GetGroup(), req.GetTopic(), start, start).
+				Suffix("ON CONFLICT DO NOTHING").
+				ExecContext(ctx)
+			if err != nil {
+				return err
+			}
+			claimed, err = s.lockGroup(ctx, tx, group)
+		}
+		if err != nil {
+			return err
+		}
+		return fn(tx, group, claimed)
+	})
+}
+
+// inTx runs fn in the transaction the server is bound to or in a new transaction which is committed if fn succeeds
+func (s *eventsServer) inTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	if tx, ok := s.db.(*sql.Tx); ok {
+		return fn(tx)
+	}
+	rawDB, ok := s.db.(*sql.DB)
+	if !ok {
+		return errors.New("unsupported database handle")
+	}
+	tx, err := rawDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	return fn(tx)
+}
+
+// lockGroup returns the claimed sequence of a consumer group and locks the group until the transaction ends
+func (s *eventsServer) lockGroup(ctx context.Context, tx *sql.Tx, group squirrel.Eq) (claimed uint64, err error) {
+	err = s.getBuilder(tx).Select("claimed_sequence").
+		From(s.table("consumer_groups")).
+		Where(group).
+		Suffix("FOR UPDATE").
+		QueryRowContext(ctx).Scan(&claimed)
+	return claimed, err
+}
+
+// commitGroup stores the claimed sequence of a consumer group and commits all events before the first unacknowledged delivery
+func (s *eventsServer) commitGroup(ctx context.Context, tx *sql.Tx, group squirrel.Eq, claimed uint64) error {
+	var firstPending sql.NullInt64
+	err := s.getBuilder(tx).Select("MIN(sequence)").
+		From(s.table("event_deliveries")).
+		Where(group).
+		QueryRowContext(ctx).Scan(&firstPending)
+	if err != nil {
+		return err
+	}
+	committed := claimed
+	if firstPending.Valid {
+		committed = uint64(firstPending.Int64) - 1
+	}
+	_, err = s.getBuilder(tx).Update(s.table("consumer_groups")).
+		Set("claimed_sequence", claimed).
+		Set("committed_sequence", committed).
+		Set("updated_at", time.Now()).
+		Where(group).
+		ExecContext(ctx)
+	return err
+}
+
+// initialSequence returns the sequence after which a new consumer group starts
+func (s *eventsServer) initialSequence(ctx context.Context, tx *sql.Tx, ns string, req *api.SubscribeRequest) (uint64, error) {
+	if req.GetSinceSequence() > 0 {
+		return req.GetSinceSequence(), nil
+	}
+	if req.GetSinceCreatedAt() == nil {
+		return 0, nil
+	}
+	ts, err := ptypes.Timestamp(req.GetSinceCreatedAt())
+	if err != nil {
+		return 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var seq uint64
+	err = s.getBuilder(tx).Select("COALESCE(MAX(sequence), 0)").
+		From(s.table("events")).
+		Where(squirrel.Eq{"namespace": ns}).
+		Where(topicFilter(req.GetTopic())).
+		Where(squirrel.Lt{"created_at": ts}).
+		QueryRowContext(ctx).Scan(&seq)
+	return seq, err
+}
+
+// eventFilter selects the events of a topic or pattern after the given sequence, matching the labels and created after since
+func eventFilter(ns, topic string, labels map[string]string, afterSequence uint64, since *timestamp.Timestamp) (squirrel.And, error) {
+	filter := squirrel.And{
+		squirrel.Eq{"namespace": ns},
+		topicFilter(topic),
+	}
+	if afterSequence > 0 {
+		filter = append(filter, squirrel.Gt{
+			"sequence": afterSequence,
+		})
+	}
+	if labels != nil {
+		filter = append(filter, sqlizers.JSONContains{
+			"labels": dbtypes.JSONBlob(labels),
+		})
+	}
+	if since != nil {
+		ts, err := ptypes.Timestamp(since)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, squirrel.GtOrEq{
+			"created_at": ts,
+		})
+	}
+	return filter, nil
+}
+
+// topicFilter matches the topic column against a topic or pattern
+func topicFilter(topic string) squirrel.Sqlizer {
+	if channels.IsPattern(topic) {
+		return squirrel.Expr("topic ~ ?", channels.TopicRegexp(topic))
+	}
+	return squirrel.Eq{"topic": topic}
+}
+
+// eventColumns are the columns read by scanEvent
+var eventColumns = []string{"event_id", "topic", "labels", "payload", "created_at", "sequence", "topic_sequence"}
+
+func scanEvent(rows *sql.Rows, ns string) (*api.Event, error) {
+	var (
+		event     = &api.Event{Namespace: ns}
+		createdAt time.Time
+		err       error
+	)
+	err = rows.Scan(&event.Id, &event.Topic, dbtypes.JSONBlob(&event.Labels), &event.Payload, &createdAt, &event.Sequence, &event.TopicSequence)
+	if err != nil {
+		return nil, err
+	}
+	event.CreatedAt, err = ptypes.TimestampProto(createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// scanEvents reads and closes rows selected like in scanEvent
+func scanEvents(rows *sql.Rows, ns string) ([]*api.Event, error) {
+	defer rows.Close()
+	var events []*api.Event
+	for rows.Next() {
+		event, err := scanEvent(rows, ns)
+		if err != nil {
+			return nil, err
 		}
+		events = append(events, event)
 	}
+	return events, rows.Err()
 }
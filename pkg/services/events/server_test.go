@@ -0,0 +1,479 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/internal/fakes"
+	"github.com/trusch/backbone-tools/pkg/internal/testdb"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestServer creates an events server working in a fresh schema which is dropped by the returned cleanup function
+func newTestServer(t *testing.T) (*eventsServer, func()) {
+	db, schema, cleanup := testdb.New(t, "events")
+	srv, err := NewServer(context.Background(), db, testdb.ConnectString(), schema)
+	require.NoError(t, err)
+	return srv.(*eventsServer), cleanup
+}
+
+func publish(t *testing.T, srv *eventsServer, topic string, n int) {
+	for i := 0; i < n; i++ {
+		_, err := srv.Publish(context.Background(), &api.PublishRequest{
+			Topic:   topic,
+			Payload: []byte(fmt.Sprint(i)),
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestConsumerGroupDistributesEvents(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	publish(t, srv, "orders", 50)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		received = make(map[uint64]int)
+		members  = make(map[int]int)
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(member int) {
+			defer wg.Done()
+			stream := &fakes.SubscribeStream{Ctx: ctx, OnSend: func(event *api.Event) {
+				mu.Lock()
+				defer mu.Unlock()
+				received[event.GetSequence()]++
+				members[member]++
+				if len(received) == 50 {
+					cancel()
+				}
+				// give the other members a chance to claim
+				time.Sleep(time.Millisecond)
+			}}
+			_ = srv.Subscribe(&api.SubscribeRequest{Topic: "orders", Group: "billing"}, stream)
+		}(i)
+	}
+	wg.Wait()
+
+	require.Len(t, received, 50)
+	for seq, n := range received {
+		require.Equal(t, 1, n, "event %d was delivered %d times", seq, n)
+	}
+	require.True(t, len(members) > 1, "events were not distributed among the members")
+}
+
+func TestConsumerGroupResumes(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	publish(t, srv, "orders", 5)
+
+	consume := func(group string, want int) []uint64 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var seqs []uint64
+		stream := &fakes.SubscribeStream{Ctx: ctx, OnSend: func(event *api.Event) {
+			seqs = append(seqs, event.GetSequence())
+			if len(seqs) == want {
+				cancel()
+			}
+		}}
+		_ = srv.Subscribe(&api.SubscribeRequest{Topic: "orders", Group: group}, stream)
+		return seqs
+	}
+
+	first := consume("billing", 5)
+	require.Len(t, first, 5)
+
+	// a reconnecting member only gets new events
+	publish(t, srv, "orders", 2)
+	second := consume("billing", 2)
+	require.Len(t, second, 2)
+	require.True(t, second[0] > first[4])
+
+	// other groups start from the beginning
+	require.Len(t, consume("audit", 7), 7)
+}
+
+func committedSequence(t *testing.T, srv *eventsServer, group string) uint64 {
+	var seq uint64
+	err := srv.getBuilder(srv.db).Select("committed_sequence").
+		From(srv.table("consumer_groups")).
+		Where("group_name = ?", group).
+		QueryRowContext(context.Background()).Scan(&seq)
+	require.NoError(t, err)
+	return seq
+}
+
+func TestConsumeRedeliversUnacknowledged(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = 50 * time.Millisecond
+	publish(t, srv, "orders", 3)
+
+	// consume receives want events and acknowledges them if ack is set
+	consume := func(want int, ack bool) []uint64 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var (
+			mu     sync.Mutex
+			seqs   []uint64
+			stream = &fakes.ConsumeStream{Reqs: make(chan *api.ConsumeRequest, 10)}
+		)
+		stream.Ctx = ctx
+		stream.OnSend = func(event *api.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			seqs = append(seqs, event.GetSequence())
+			if ack {
+				stream.Reqs <- &api.ConsumeRequest{Acks: []uint64{event.GetSequence()}}
+			}
+		}
+		stream.Reqs <- &api.ConsumeRequest{
+			Subscribe:         &api.SubscribeRequest{Topic: "orders", Group: "billing"},
+			VisibilityTimeout: ptypes.DurationProto(200 * time.Millisecond),
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = srv.Consume(stream)
+		}()
+		for ctx.Err() == nil {
+			mu.Lock()
+			n := len(seqs)
+			mu.Unlock()
+			if n == want && (!ack || committedSequence(t, srv, "billing") == seqs[n-1]) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel()
+		<-done
+		return seqs
+	}
+
+	// the first consumer crashes without acknowledging
+	first := consume(3, false)
+	require.Len(t, first, 3)
+	require.Equal(t, uint64(0), committedSequence(t, srv, "billing"))
+
+	// the events are delivered again after the visibility timeout
+	second := consume(3, true)
+	require.Equal(t, first, second)
+	require.Equal(t, first[2], committedSequence(t, srv, "billing"))
+
+	// acknowledged events are not delivered again
+	publish(t, srv, "orders", 1)
+	third := consume(1, true)
+	require.Len(t, third, 1)
+	require.True(t, third[0] > first[2])
+}
+
+func TestTopicPatterns(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	for _, topic := range []string{"orders.eu.created", "orders.us.created", "orders.eu.deleted", "invoices.created"} {
+		publish(t, srv, topic, 1)
+	}
+
+	// subscribe collects the topics of want events, publishing the live topics after subscribing
+	subscribe := func(pattern string, want int, live ...string) []string {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var (
+			mu     sync.Mutex
+			topics []string
+		)
+		stream := &fakes.SubscribeStream{Ctx: ctx, OnSend: func(event *api.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			topics = append(topics, event.GetTopic())
+			if len(topics) == want {
+				cancel()
+			}
+		}}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = srv.Subscribe(&api.SubscribeRequest{Topic: pattern}, stream)
+		}()
+		// the default poll interval is far longer than the test timeout, live events arrive by notification
+		time.Sleep(100 * time.Millisecond)
+		for _, topic := range live {
+			publish(t, srv, topic, 1)
+		}
+		<-done
+		mu.Lock()
+		defer mu.Unlock()
+		return topics
+	}
+
+	require.Equal(t, []string{"orders.eu.created", "orders.us.created"}, subscribe("orders.*.created", 2))
+	require.Equal(t, []string{"orders.eu.created", "orders.us.created", "orders.eu.deleted"}, subscribe("orders.>", 3))
+	require.Equal(t, []string{"invoices.created"}, subscribe("*.created", 1))
+	require.Equal(t,
+		[]string{"orders.eu.created", "orders.us.created", "orders.eu.deleted", "orders.eu.shipped"},
+		subscribe("orders.>", 4, "invoices.paid", "orders.eu.shipped"),
+	)
+}
+
+func TestConcurrentPublishesAreNotSkipped(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	// poll as fast as possible, so the subscriber reads while publishes are in flight
+	pollInterval = time.Millisecond
+
+	const publishers, perPublisher = 10, 20
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		received []*api.Event
+	)
+	stream := &fakes.SubscribeStream{Ctx: ctx, OnSend: func(event *api.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+		if len(received) == publishers*perPublisher {
+			cancel()
+		}
+	}}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = srv.Subscribe(&api.SubscribeRequest{Topic: "orders.>"}, stream)
+	}()
+
+	// require must not be called off the test goroutine, so publish errors are collected
+	errs := make(chan error, publishers)
+	var wg sync.WaitGroup
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perPublisher; j++ {
+				_, err := srv.Publish(ctx, &api.PublishRequest{
+					Topic:   fmt.Sprintf("orders.%d", i%3),
+					Payload: []byte(fmt.Sprint(j)),
+				})
+				if err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, publishers*perPublisher, "the subscriber skipped events")
+	topicSequences := make(map[string]uint64)
+	for i, event := range received {
+		if i > 0 {
+			require.True(t, event.GetSequence() > received[i-1].GetSequence())
+		}
+		// topic sequences are dense
+		topicSequences[event.GetTopic()]++
+		require.Equal(t, topicSequences[event.GetTopic()], event.GetTopicSequence())
+	}
+}
+
+// TestPublishTransactionBlocksItsNamespace shows the throughput limit of publishing:
+// one transaction at a time per namespace
+func TestPublishTransactionBlocksItsNamespace(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+	ctxA := namespace.NewContext(ctx, "team-a")
+	ctxB := namespace.NewContext(ctx, "team-b")
+
+	tx, err := srv.db.(*sql.DB).BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, err = NewTxServer(tx, srv.schema).Publish(ctxA, &api.PublishRequest{Topic: "orders"})
+	require.NoError(t, err)
+
+	// other namespaces are not affected
+	timeoutCtx, cancel := context.WithTimeout(ctxB, 5*time.Second)
+	defer cancel()
+	_, err = srv.Publish(timeoutCtx, &api.PublishRequest{Topic: "orders"})
+	require.NoError(t, err)
+
+	// publishes to the namespace wait for the transaction, even on other topics
+	published := make(chan error, 1)
+	go func() {
+		_, err := srv.Publish(ctxA, &api.PublishRequest{Topic: "invoices"})
+		published <- err
+	}()
+	select {
+	case err := <-published:
+		t.Fatalf("publish did not wait for the transaction: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+	require.NoError(t, tx.Commit())
+	select {
+	case err := <-published:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish still waits after the transaction committed")
+	}
+}
+
+func TestPublishBatch(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	publish(t, srv, "orders.created", 1)
+
+	var reqs []*api.PublishRequest
+	for i := 0; i < 5; i++ {
+		reqs = append(reqs, &api.PublishRequest{
+			Topic:   []string{"orders.created", "orders.deleted"}[i%2],
+			Payload: []byte(fmt.Sprint(i)),
+		})
+	}
+	resp, err := srv.PublishBatch(context.Background(), &api.PublishBatchRequest{Events: reqs})
+	require.NoError(t, err)
+	require.Len(t, resp.GetEvents(), 5)
+	for i, event := range resp.GetEvents() {
+		require.Equal(t, reqs[i].GetTopic(), event.GetTopic())
+		require.Empty(t, event.GetPayload())
+		if i > 0 {
+			require.True(t, event.GetSequence() > resp.GetEvents()[i-1].GetSequence())
+		}
+	}
+	topicSequences := []uint64{2, 1, 3, 2, 4}
+	for i, event := range resp.GetEvents() {
+		require.Equal(t, topicSequences[i], event.GetTopicSequence())
+	}
+
+	stream := &fakes.PublishStream{Reqs: reqs[:2]}
+	require.NoError(t, srv.PublishStream(stream))
+	require.Len(t, stream.Resp.GetEvents(), 2)
+	require.True(t, stream.Resp.GetEvents()[0].GetSequence() > resp.GetEvents()[4].GetSequence())
+	require.Equal(t, uint64(5), stream.Resp.GetEvents()[0].GetTopicSequence())
+
+	_, err = srv.PublishBatch(context.Background(), &api.PublishBatchRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = srv.PublishBatch(context.Background(), &api.PublishBatchRequest{Events: []*api.PublishRequest{{Topic: "orders.*"}}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestExportAndReplay(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+	publish(t, srv, "orders.eu", 2)
+	mid, err := ptypes.TimestampProto(time.Now())
+	require.NoError(t, err)
+	publish(t, srv, "orders.eu", 1)
+	publish(t, srv, "orders.us", 2)
+	publish(t, srv, "invoices", 1)
+
+	export := func(req *api.ExportRequest) (events []*api.Event) {
+		stream := &fakes.SubscribeStream{Ctx: ctx, OnSend: func(event *api.Event) {
+			events = append(events, event)
+		}}
+		require.NoError(t, srv.Export(req, stream))
+		return events
+	}
+	require.Len(t, export(&api.ExportRequest{Topic: "orders.>"}), 5)
+	require.Len(t, export(&api.ExportRequest{Topic: "orders.eu", To: mid}), 2)
+	window := export(&api.ExportRequest{Topic: "orders.>", From: mid})
+	require.Len(t, window, 3)
+
+	resp, err := srv.Replay(ctx, &api.ReplayRequest{Topic: "orders.>", From: mid, TargetTopic: "replayed.orders"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), resp.GetEvents())
+	copies := export(&api.ExportRequest{Topic: "replayed.orders"})
+	require.Len(t, copies, 3)
+	require.Equal(t, resp.GetFirstSequence(), copies[0].GetSequence())
+	require.Equal(t, resp.GetLastSequence(), copies[2].GetSequence())
+	for i, event := range copies {
+		require.Equal(t, window[i].GetPayload(), event.GetPayload())
+		require.Equal(t, window[i].GetTopic(), event.GetLabels()[replayedTopicLabel])
+		require.Equal(t, fmt.Sprint(window[i].GetSequence()), event.GetLabels()[replayedSequenceLabel])
+	}
+
+	_, err = srv.Replay(ctx, &api.ReplayRequest{Topic: "orders.>", TargetTopic: "orders.copy"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// large windows are rejected instead of blocking publishes for the whole copy
+	defer func(size int) { maxReplaySize = size }(maxReplaySize)
+	maxReplaySize = 4
+	_, err = srv.Replay(ctx, &api.ReplayRequest{Topic: "orders.>", TargetTopic: "replayed.all"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Empty(t, export(&api.ExportRequest{Topic: "replayed.all"}))
+	resp, err = srv.Replay(ctx, &api.ReplayRequest{Topic: "orders.eu", TargetTopic: "replayed.all"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), resp.GetEvents())
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctxA := namespace.NewContext(context.Background(), "team-a")
+	ctxB := namespace.NewContext(context.Background(), "team-b")
+	registry, err := NewSchemasServer(ctxA, srv.db.(*sql.DB), srv.schema)
+	require.NoError(t, err)
+
+	_, err = registry.Register(ctxA, &api.RegisterSchemaRequest{Topic: "orders", Schema: `{"type": "object"}`})
+	require.NoError(t, err)
+	_, err = registry.Get(ctxB, &api.GetSchemaRequest{Topic: "orders"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// a subscriber of another namespace is neither woken by nor receives the events
+	subCtx, cancel := context.WithCancel(ctxB)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		received []*api.Event
+		done     = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		stream := &fakes.SubscribeStream{Ctx: subCtx, OnSend: func(event *api.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, event)
+		}}
+		_ = srv.Subscribe(&api.SubscribeRequest{Topic: "orders", Group: "audit"}, stream)
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	_, err = srv.Publish(ctxA, &api.PublishRequest{Topic: "orders", Payload: []byte(`{"id": 1}`)})
+	require.NoError(t, err)
+	// the schema of the other namespace doesn't apply
+	_, err = srv.Publish(ctxB, &api.PublishRequest{Topic: "orders", Payload: []byte("not json")})
+	require.NoError(t, err)
+
+	time.Sleep(time.Second)
+	cancel()
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	require.Equal(t, "team-b", received[0].GetNamespace())
+	require.Equal(t, []byte("not json"), received[0].GetPayload())
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -15,8 +14,14 @@ import (
 	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/errmap"
+	"github.com/trusch/backbone-tools/pkg/namespace"
 	"github.com/trusch/backbone-tools/pkg/sqlizers"
 	"github.com/trusch/backbone-tools/pkg/ticker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -24,36 +29,61 @@ var (
 	heartbeatDeadline = 20 * time.Second
 )
 
-func NewServer(ctx context.Context, db *sql.DB, connectString string) (api.JobsServer, error) {
+// maxClaimAttempts bounds the immediate retries of a listener losing a claim to a concurrent one
+const maxClaimAttempts = 5
+
+func NewServer(ctx context.Context, db *sql.DB, connectString, schema string) (api.JobsServer, error) {
 	srv := &jobsServer{
 		Tracer:        tracing.NewTracer("jobs", "JobsServer"),
 		db:            db,
 		connectString: connectString,
+		schema:        schema,
 	}
-	return srv, srv.init(ctx)
+	return srv, nil
+}
+
+// NewTxServer creates a jobs server running all statements in tx, so jobs can be changed atomically with other data.
+// Notifications are delivered when tx commits, Listen is not supported.
+func NewTxServer(tx *sql.Tx, schema string) api.JobsServer {
+	return &jobsServer{tracing.NewTracer("jobs", "JobsServer"), tx, "", schema}
 }
 
 type jobsServer struct {
 	tracing.Tracer
 	db            squirrel.StdSqlCtx
 	connectString string
+	schema        string
 }
 
-func (s *jobsServer) init(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `
-CREATE TABLE IF NOT EXISTS jobs(
-  job_id UUID PRIMARY KEY,
-  queue TEXT NOT NULL,
-  spec BYTEA,
-  labels JSONB NOT NULL DEFAULT '{}',
-  state BYTEA,
-  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-  started_at TIMESTAMPTZ,
-  updated_at TIMESTAMPTZ,
-  finished_at TIMESTAMPTZ
-);
-`)
-	return err
+func (s *jobsServer) table(name string) string {
+	return sqlizers.Table(s.schema, name)
+}
+
+func (s *jobsServer) withTx(tx *sql.Tx) *jobsServer {
+	return &jobsServer{s.Tracer, tx, s.connectString, s.schema}
+}
+
+// inTx runs fn in the transaction the server is bound to or in a new transaction which is committed if fn succeeds
+func (s *jobsServer) inTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	if tx, ok := s.db.(*sql.Tx); ok {
+		return fn(tx)
+	}
+	rawDB, ok := s.db.(*sql.DB)
+	if !ok {
+		return errors.New("unsupported database handle")
+	}
+	tx, err := rawDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	return fn(tx)
 }
 
 func (s *jobsServer) getBuilder(db squirrel.BaseRunner) squirrel.StatementBuilderType {
@@ -67,6 +97,16 @@ func (s *jobsServer) Create(ctx context.Context, req *api.CreateJobRequest) (job
 	defer func() {
 		s.FinishSpan(span, err)
 	}()
+	if err := channels.ValidateName("queue", req.GetQueue()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "jobs:create", req.GetQueue()); err != nil {
+		return nil, err
+	}
 	id := uuid.NewV4().String()
 	now := time.Now()
 	nowProto, err := ptypes.TimestampProto(now)
@@ -79,18 +119,21 @@ func (s *jobsServer) Create(ctx context.Context, req *api.CreateJobRequest) (job
 	}
 
 	span.SetTag("job_id", id)
+	span.SetTag("namespace", ns)
 	span.SetTag("queue", req.GetQueue())
 	span.SetTag("spec", string(req.GetSpec()))
 	span.SetTag("labels", req.GetLabels())
 
-	_, err = s.getBuilder(s.db).Insert("jobs").Columns(
+	_, err = s.getBuilder(s.db).Insert(s.table("jobs")).Columns(
 		"job_id",
+		"namespace",
 		"queue",
 		"labels",
 		"spec",
 		"created_at",
 	).Values(
 		id,
+		ns,
 		req.GetQueue(),
 		dbtypes.JSONBlob(req.GetLabels()),
 		req.GetSpec(),
@@ -100,13 +143,14 @@ func (s *jobsServer) Create(ctx context.Context, req *api.CreateJobRequest) (job
 		return nil, err
 	}
 
-	_, err = s.db.ExecContext(ctx, `NOTIFY `+req.GetQueue())
+	err = channels.Notify(ctx, s.db, channels.Jobs(s.schema, ns, req.GetQueue()))
 	if err != nil {
 		return nil, err
 	}
 
 	return &api.Job{
 		Id:        id,
+		Namespace: ns,
 		Queue:     req.GetQueue(),
 		Labels:    req.GetLabels(),
 		Spec:      req.GetSpec(),
@@ -121,189 +165,203 @@ func (s *jobsServer) Listen(req *api.ListenRequest, resp api.Jobs_ListenServer)
 	}()
 
 	span.SetTag("queue", req.GetQueue())
+	if err := channels.ValidateName("queue", req.GetQueue()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, ns, "jobs:listen", req.GetQueue()); err != nil {
+		return err
+	}
+	span.SetTag("namespace", ns)
 
 	notifyConn, err := pgx.Connect(ctx, s.connectString)
 	if err != nil {
 		return err
 	}
-
-	ticker := ticker.New(pollInterval, 0.1, notifyConn, req.GetQueue())
+	defer notifyConn.Close(context.Background())
+	ticker := ticker.New(pollInterval, 0.1, notifyConn, channels.Jobs(s.schema, ns, req.GetQueue()))
 	if err := ticker.Start(ctx); err != nil {
 		return err
 	}
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			var job *api.Job
-			// start tx in lambda to use defer syntax
-			err := func() (err error) {
-				span, ctx := s.StartSpan(ctx, "startJob")
-				defer func() {
-					s.FinishSpan(span, err)
-				}()
-
-				// setup tx
-				rawDB, ok := s.db.(*sql.DB)
-				if !ok {
-					return errors.New("can not listen withing transactions")
+			// concurrent listeners conflict when claiming the same job, the losers retry right away
+			var (
+				job *api.Job
+				err error
+			)
+			for attempt := 1; ; attempt++ {
+				job, err = s.startJob(ctx, ns, req.GetQueue())
+				if !errmap.IsSerializationFailure(err) || attempt == maxClaimAttempts {
+					break
 				}
-				tx, err := rawDB.BeginTx(ctx, &sql.TxOptions{
-					Isolation: sql.LevelSerializable,
-				})
-				if err != nil {
-					return err
-				}
-				defer func() {
-					if err != nil {
-						logrus.Errorf("error while listening: %v", err)
-						tx.Rollback()
-						return
-					}
-					logrus.Infof("committing")
-					err = tx.Commit()
-				}()
-
-				// get job
-				job, err = s.getJob(ctx, tx, req.GetQueue())
-				if err != nil {
-					return err
-				}
-
-				span.SetTag("job_id", job.GetId())
-				span.SetTag("queue", job.GetQueue())
-				span.SetTag("spec", job.GetSpec())
-
-				// set started_at
-				now := time.Now()
-				nowProto, err := ptypes.TimestampProto(now)
-				if err != nil {
-					return err
-				}
-				_, err = s.getBuilder(tx).Update("jobs").
-					Set("started_at", now).
-					Set("updated_at", now).
-					Where(squirrel.Eq{"job_id": job.GetId()}).
-					ExecContext(ctx)
-				job.StartedAt = nowProto
-
-				return err
-			}()
-
+			}
+			if err == sql.ErrNoRows {
+				continue
+			}
 			if err != nil {
-				if err == sql.ErrNoRows {
-					continue
-				}
-				if strings.HasPrefix(err.Error(), "pq: could not serialize access") {
-					continue
-				}
 				return err
 			}
 
 			// send job to worker
-			if job != nil {
-				logrus.Infof("found job while listening: %+v", job)
-				err = resp.Send(job)
-				if err != nil {
-					return err
-				}
-				job = nil
+			logrus.Infof("found job while listening: %+v", job)
+			err = resp.Send(job)
+			if err != nil {
+				return err
 			}
 		}
 	}
 }
 
-func (s *jobsServer) getJob(ctx context.Context, tx *sql.Tx, queue string) (*api.Job, error) {
-	var (
-		job       api.Job
-		createdAt time.Time
-	)
-	pred := squirrel.And{
-		squirrel.Eq{
-			"queue":       queue,
-			"finished_at": nil,
-		},
-		squirrel.Or{
-			// not started
-			squirrel.Eq{"started_at": nil},
-			// job got heartbeats but the last heartbeat is too long ago
-			squirrel.Lt{"updated_at": time.Now().Add(-heartbeatDeadline)},
-		},
-	}
-	err := s.getBuilder(tx).Select("job_id", "spec", "created_at", "labels").
-		From("jobs").
-		Where(pred).
-		OrderBy("created_at ASC").
-		Limit(1).
-		QueryRowContext(ctx).Scan(&job.Id, &job.Spec, &createdAt, dbtypes.JSONBlob(&job.Labels))
-	if err != nil {
-		return nil, err
-	}
-	job.CreatedAt, err = ptypes.TimestampProto(createdAt)
-	if err != nil {
-		return nil, err
-	}
-	return &job, nil
-}
-
-func (s *jobsServer) Heartbeat(ctx context.Context, req *api.HeartbeatRequest) (job *api.Job, err error) {
-	span, ctx := s.StartSpan(ctx, "Heartbeat")
+// startJob claims the oldest claimable job of a queue and marks it as started
+func (s *jobsServer) startJob(ctx context.Context, ns, queue string) (job *api.Job, err error) {
+	span, ctx := s.StartSpan(ctx, "startJob")
 	defer func() {
 		s.FinishSpan(span, err)
 	}()
+
 	// setup tx
 	rawDB, ok := s.db.(*sql.DB)
 	if !ok {
-		return nil, errors.New("can not start transactions withing transactions")
+		return nil, errors.New("can not listen withing transactions")
 	}
-	tx, err := rawDB.BeginTx(ctx, nil)
+	tx, err := rawDB.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		if err != nil {
+			logrus.Errorf("error while listening: %v", err)
 			tx.Rollback()
 			return
 		}
+		logrus.Infof("committing")
 		err = tx.Commit()
 	}()
 
 	// get job
-	job, err = (&jobsServer{s.Tracer, tx, ""}).Get(ctx, &api.GetRequest{Id: req.GetJobId()})
+	job, err = s.getJob(ctx, tx, ns, queue)
 	if err != nil {
 		return nil, err
 	}
 
-	// update object
+	span.SetTag("job_id", job.GetId())
+	span.SetTag("queue", job.GetQueue())
+	span.SetTag("spec", job.GetSpec())
+
+	// set started_at
 	now := time.Now()
 	nowProto, err := ptypes.TimestampProto(now)
 	if err != nil {
 		return nil, err
 	}
-	job.UpdatedAt = nowProto
-	job.State = req.GetState()
-	if req.GetFinished() {
-		job.FinishedAt = nowProto
+	_, err = s.getBuilder(tx).Update(s.table("jobs")).
+		Set("started_at", now).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"job_id": job.GetId()}).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
 	}
+	job.StartedAt = nowProto
+	return job, nil
+}
 
-	// persist new values in db
-	builder := s.getBuilder(tx).Update("jobs").
-		Set("updated_at", now)
-	if state := req.GetState(); state != nil {
-		builder = builder.Set("state", state)
+func (s *jobsServer) getJob(ctx context.Context, tx *sql.Tx, ns, queue string) (*api.Job, error) {
+	var (
+		job       = api.Job{Namespace: ns, Queue: queue}
+		createdAt time.Time
+	)
+	err := s.claimQuery(s.getBuilder(tx), ns, queue).
+		QueryRowContext(ctx).Scan(&job.Id, &job.Spec, &createdAt, dbtypes.JSONBlob(&job.Labels))
+	if err != nil {
+		return nil, err
+	}
+	job.CreatedAt, err = ptypes.TimestampProto(createdAt)
+	if err != nil {
+		return nil, err
 	}
-	if req.GetFinished() {
-		builder = builder.Set("finished_at", now)
+	return &job, nil
+}
+
+// claimQuery selects the oldest claimable job of a queue, it is served by the partial index jobs_claim_idx
+func (s *jobsServer) claimQuery(builder squirrel.StatementBuilderType, ns, queue string) squirrel.SelectBuilder {
+	pred := squirrel.And{
+		squirrel.Eq{
+			"namespace":   ns,
+			"queue":       queue,
+			"finished_at": nil,
+		},
+		squirrel.Or{
+			// not started
+			squirrel.Eq{"started_at": nil},
+			// job got heartbeats but the last heartbeat is too long ago
+			squirrel.Lt{"updated_at": time.Now().Add(-heartbeatDeadline)},
+		},
 	}
-	builder = builder.Where(squirrel.Eq{
-		"job_id": req.GetJobId(),
+	return builder.Select("job_id", "spec", "created_at", "labels").
+		From(s.table("jobs")).
+		Where(pred).
+		OrderBy("created_at ASC").
+		Limit(1)
+}
+
+func (s *jobsServer) Heartbeat(ctx context.Context, req *api.HeartbeatRequest) (job *api.Job, err error) {
+	span, ctx := s.StartSpan(ctx, "Heartbeat")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		// get job
+		var err error
+		job, err = s.withTx(tx).Get(auth.NewSystemContext(ctx), &api.GetRequest{Id: req.GetJobId()})
+		if err != nil {
+			return err
+		}
+		if err := authorizeJob(ctx, "jobs:heartbeat", job); err != nil {
+			return err
+		}
+
+		// update object
+		now := time.Now()
+		nowProto, err := ptypes.TimestampProto(now)
+		if err != nil {
+			return err
+		}
+		job.UpdatedAt = nowProto
+		job.State = req.GetState()
+		if req.GetFinished() {
+			job.FinishedAt = nowProto
+		}
+
+		// persist new values in db
+		builder := s.getBuilder(tx).Update(s.table("jobs")).
+			Set("updated_at", now)
+		if state := req.GetState(); state != nil {
+			builder = builder.Set("state", state)
+		}
+		if req.GetFinished() {
+			builder = builder.Set("finished_at", now)
+		}
+		builder = builder.Where(squirrel.Eq{
+			"job_id":    job.GetId(),
+			"namespace": job.GetNamespace(),
+		})
+		_, err = builder.ExecContext(ctx)
+		return err
 	})
-	_, err = builder.ExecContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-
 	return job, nil
 }
 
@@ -314,23 +372,34 @@ func (s *jobsServer) Get(ctx context.Context, req *api.GetRequest) (job *api.Job
 	}()
 	span.SetTag("job_id", req.GetId())
 	span.SetTag("name", req.GetName())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	job = &api.Job{}
+	job = &api.Job{Namespace: ns}
 	var (
 		createdAt  time.Time
 		updatedAt  *time.Time
 		startedAt  *time.Time
 		finishedAt *time.Time
 	)
-	err = s.getBuilder(s.db).Select("job_id", "spec", "state", "labels", "created_at", "updated_at", "started_at", "finished_at").
-		From("jobs").
+	err = s.getBuilder(s.db).Select("job_id", "queue", "spec", "state", "labels", "created_at", "updated_at", "started_at", "finished_at").
+		From(s.table("jobs")).
 		Where(squirrel.Eq{
-			"job_id": req.GetId(),
+			"job_id":    req.GetId(),
+			"namespace": ns,
 		}).
-		QueryRowContext(ctx).Scan(&job.Id, &job.Spec, &job.State, dbtypes.JSONBlob(&job.Labels), &createdAt, &updatedAt, &startedAt, &finishedAt)
+		QueryRowContext(ctx).Scan(&job.Id, &job.Queue, &job.Spec, &job.State, dbtypes.JSONBlob(&job.Labels), &createdAt, &updatedAt, &startedAt, &finishedAt)
+	if err == sql.ErrNoRows {
+		return nil, errJobNotFound(req.GetId())
+	}
 	if err != nil {
 		return nil, err
 	}
+	if err := authorizeJob(ctx, "jobs:get", job); err != nil {
+		return nil, err
+	}
 	job.CreatedAt, err = ptypes.TimestampProto(createdAt)
 	if err != nil {
 		return nil, err
@@ -364,37 +433,26 @@ func (s *jobsServer) Delete(ctx context.Context, req *api.DeleteRequest) (job *a
 	span.SetTag("job_id", req.GetId())
 	span.SetTag("name", req.GetName())
 
-	// setup tx
-	rawDB, ok := s.db.(*sql.DB)
-	if !ok {
-		return nil, errors.New("can not start transactions withing transactions")
-	}
-	tx, err := rawDB.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		// get job
+		var err error
+		job, err = s.withTx(tx).Get(auth.NewSystemContext(ctx), &api.GetRequest{Id: req.GetId(), Name: req.GetName()})
 		if err != nil {
-			tx.Rollback()
-			return
+			return err
+		}
+		if err := authorizeJob(ctx, "jobs:delete", job); err != nil {
+			return err
 		}
-		err = tx.Commit()
-	}()
-
-	// get job
-	job, err = (&jobsServer{s.Tracer, tx, ""}).Get(ctx, &api.GetRequest{Id: req.GetId(), Name: req.GetName()})
-	if err != nil {
-		return nil, err
-	}
 
-	_, err = s.getBuilder(tx).
-		Delete("jobs").
-		Where(squirrel.Eq{"job_id": job.GetId()}).
-		ExecContext(ctx)
+		_, err = s.getBuilder(tx).
+			Delete(s.table("jobs")).
+			Where(squirrel.Eq{"job_id": job.GetId(), "namespace": job.GetNamespace()}).
+			ExecContext(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-
 	return job, nil
 }
 
@@ -409,8 +467,26 @@ func (s *jobsServer) List(req *api.ListRequest, resp api.Jobs_ListServer) (err e
 	span.SetTag("queues", req.GetQueues())
 	span.SetTag("labels", req.GetLabels())
 	span.SetTag("exclude_finished", req.GetExcludeFinished())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	span.SetTag("namespace", ns)
 
-	filter := squirrel.And{}
+	filter := squirrel.And{squirrel.Eq{"namespace": ns}}
+	for _, queue := range req.GetQueues() {
+		if err := channels.ValidateName("queue", queue); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := auth.Authorize(ctx, ns, "jobs:list", queue); err != nil {
+			return err
+		}
+	}
+	if len(req.GetQueues()) == 0 {
+		if err := auth.Authorize(ctx, ns, "jobs:list", "*"); err != nil {
+			return err
+		}
+	}
 	if queues := req.GetQueues(); len(queues) > 0 {
 		filter = append(filter, squirrel.Eq{
 			"queue": req.GetQueues(),
@@ -426,7 +502,7 @@ func (s *jobsServer) List(req *api.ListRequest, resp api.Jobs_ListServer) (err e
 	}
 	rows, err := s.getBuilder(s.db).
 		Select("job_id", "spec", "state", "queue", "labels", "created_at", "updated_at", "started_at", "finished_at").
-		From("jobs").
+		From(s.table("jobs")).
 		Where(filter).
 		OrderBy("created_at ASC").
 		QueryContext(ctx)
@@ -435,7 +511,7 @@ func (s *jobsServer) List(req *api.ListRequest, resp api.Jobs_ListServer) (err e
 	}
 	for rows.Next() {
 		var (
-			job        api.Job
+			job        = api.Job{Namespace: ns}
 			createdAt  time.Time
 			updatedAt  *time.Time
 			startedAt  *time.Time
@@ -473,3 +549,17 @@ func (s *jobsServer) List(req *api.ListRequest, resp api.Jobs_ListServer) (err e
 	}
 	return nil
 }
+
+// authorizeJob checks an action on the queue of a job. Callers which may not access the queue
+// get the same error as for a missing job, so they can't find out which jobs exist.
+func authorizeJob(ctx context.Context, action string, job *api.Job) error {
+	err := auth.Authorize(ctx, job.GetNamespace(), action, job.GetQueue())
+	if status.Code(err) == codes.PermissionDenied {
+		return errJobNotFound(job.GetId())
+	}
+	return err
+}
+
+func errJobNotFound(id string) error {
+	return status.Errorf(codes.NotFound, "job %s not found", id)
+}
@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/contiamo/go-base/pkg/tracing"
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/errmap"
+	"github.com/trusch/backbone-tools/pkg/internal/testdb"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"github.com/trusch/backbone-tools/pkg/sqlizers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// seedJobs inserts finished and claimable jobs into a queue and updates the planner statistics
+func seedJobs(tb testing.TB, db *sql.DB, schema, queue string, finished, claimable int) {
+	ctx := context.Background()
+	jobs := sqlizers.Table(schema, "jobs")
+	_, err := db.ExecContext(ctx, `
+INSERT INTO `+jobs+`(job_id, queue, created_at, started_at, updated_at, finished_at)
+SELECT md5(random()::text || i::text)::uuid, $1, now() - interval '1 day', now(), now(), now()
+FROM generate_series(1, $2) AS i`, queue, finished)
+	require.NoError(tb, err)
+	_, err = db.ExecContext(ctx, `
+INSERT INTO `+jobs+`(job_id, queue)
+SELECT md5(random()::text || i::text)::uuid, $1
+FROM generate_series(1, $2) AS i`, queue, claimable)
+	require.NoError(tb, err)
+	_, err = db.ExecContext(ctx, `ANALYZE `+jobs)
+	require.NoError(tb, err)
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	ctx := context.Background()
+	db, schema, cleanup := testdb.New(t, "jobs")
+	defer cleanup()
+	srv, err := NewServer(ctx, db, testdb.ConnectString(), schema)
+	require.NoError(t, err)
+	ctxA := namespace.NewContext(ctx, "team-a")
+	ctxB := namespace.NewContext(ctx, "team-b")
+
+	job, err := srv.Create(ctxA, &api.CreateJobRequest{Queue: "reports"})
+	require.NoError(t, err)
+
+	// other namespaces neither see nor change the job
+	_, err = srv.Get(ctxB, &api.GetRequest{Id: job.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(errmap.ToStatus(err)))
+	_, err = srv.Heartbeat(ctxB, &api.HeartbeatRequest{JobId: job.GetId(), Finished: true})
+	require.Equal(t, codes.NotFound, status.Code(errmap.ToStatus(err)))
+	_, err = srv.Delete(ctxB, &api.DeleteRequest{Id: job.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(errmap.ToStatus(err)))
+
+	// and can't claim it from a queue with the same name
+	claim := func(ns string) (*api.Job, error) {
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		defer tx.Rollback()
+		return srv.(*jobsServer).getJob(ctx, tx, ns, "reports")
+	}
+	_, err = claim("team-b")
+	require.Equal(t, sql.ErrNoRows, err)
+	claimed, err := claim("team-a")
+	require.NoError(t, err)
+	require.Equal(t, job.GetId(), claimed.GetId())
+	_, err = srv.Get(ctxA, &api.GetRequest{Id: job.GetId()})
+	require.NoError(t, err)
+}
+
+// TestClaimUsesPartialIndex checks that claims don't scan finished jobs, so their latency doesn't grow with the table
+func TestClaimUsesPartialIndex(t *testing.T) {
+	ctx := context.Background()
+	db, schema, cleanup := testdb.New(t, "jobs")
+	defer cleanup()
+	srv := &jobsServer{Tracer: tracing.NewTracer("jobs", "JobsServer"), db: db, schema: schema}
+	seedJobs(t, db, schema, "claim", 20000, 10)
+
+	query, args, err := srv.claimQuery(srv.getBuilder(db), namespace.Default, "claim").ToSql()
+	require.NoError(t, err)
+	rows, err := db.QueryContext(ctx, "EXPLAIN "+query, args...)
+	require.NoError(t, err)
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan = append(plan, line)
+	}
+	require.NoError(t, rows.Err())
+	require.Contains(t, strings.Join(plan, "\n"), "jobs_claim_idx", "claim plan:\n%s", strings.Join(plan, "\n"))
+}
+
+// BenchmarkClaim measures the latency of claiming the next job of a queue
+// with a growing number of finished jobs in the table. Thanks to the partial
+// claim index the latency should stay flat.
+func BenchmarkClaim(b *testing.B) {
+	ctx := context.Background()
+	db, schema, cleanup := testdb.New(b, "jobs")
+	defer cleanup()
+	srv := &jobsServer{Tracer: tracing.NewTracer("jobs", "JobsServer"), db: db, schema: schema}
+	queue := "bench-claim"
+
+	seeded := 0
+	for _, size := range []int{10000, 100000, 1000000} {
+		// seed finished jobs up to the target size plus a few claimable ones
+		seedJobs(b, db, schema, queue, size-seeded, 100)
+		seeded = size
+
+		b.Run(fmt.Sprintf("rows=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tx, err := db.BeginTx(ctx, nil)
+				require.NoError(b, err)
+				_, err = srv.getJob(ctx, tx, namespace.Default, queue)
+				require.NoError(b, err)
+				require.NoError(b, tx.Rollback())
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package locks
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/contiamo/go-base/pkg/tracing"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/jackc/pgx/v4"
+	uuid "github.com/satori/go.uuid"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"github.com/trusch/backbone-tools/pkg/sqlizers"
+	"github.com/trusch/backbone-tools/pkg/ticker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewSemaphoresServer creates a counting semaphore service. Like locks, permits are leased
+// for a ttl, so permits of crashed holders are freed automatically.
+func NewSemaphoresServer(ctx context.Context, db *sql.DB, connectString, schema string) (api.SemaphoresServer, error) {
+	srv := &semaphoresServer{
+		Tracer:        tracing.NewTracer("locks", "SemaphoresServer"),
+		db:            db,
+		connectString: connectString,
+		schema:        schema,
+	}
+	return srv, nil
+}
+
+type semaphoresServer struct {
+	tracing.Tracer
+	db            squirrel.StdSqlCtx
+	connectString string
+	schema        string
+}
+
+func (s *semaphoresServer) table(name string) string {
+	return sqlizers.Table(s.schema, name)
+}
+
+func (s *semaphoresServer) getBuilder(db squirrel.StdSqlCtx) squirrel.StatementBuilderType {
+	return squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		RunWith(db)
+}
+
+func (s *semaphoresServer) Acquire(ctx context.Context, req *api.SemaphoreAcquireRequest) (resp *api.SemaphoreAcquireResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "Acquire")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("name", req.GetName())
+	if err := channels.ValidateName("semaphore", req.GetName()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "semaphores:acquire", req.GetName()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	permits := req.GetPermits()
+	if permits == 0 {
+		permits = 1
+	}
+	if req.GetMax() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "max must be specified")
+	}
+	if permits > req.GetMax() {
+		return nil, status.Errorf(codes.InvalidArgument, "can not take %d of %d permits", permits, req.GetMax())
+	}
+	span.SetTag("permits", permits)
+	span.SetTag("max", req.GetMax())
+	ttl, err := parseTTL(req.GetTtl())
+	if err != nil {
+		return nil, err
+	}
+	span.SetTag("ttl", ttl.String())
+
+	waitCtx := ctx
+	if req.GetWaitTimeout() != nil {
+		timeout, err := ptypes.Duration(req.GetWaitTimeout())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		span.SetTag("wait_timeout", timeout.String())
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	notifyConn, err := pgx.Connect(ctx, s.connectString)
+	if err != nil {
+		return nil, err
+	}
+	defer notifyConn.Close(context.Background())
+	ticker := ticker.New(pollInterval, 0.1, notifyConn, channels.Semaphore(s.schema, ns, req.GetName()))
+	if err := ticker.Start(waitCtx); err != nil {
+		return nil, err
+	}
+	defer ticker.Stop()
+	for {
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() == nil {
+				return nil, status.Errorf(codes.DeadlineExceeded, "timed out waiting for semaphore %s", req.GetName())
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+			err = serializable(ctx, s.db, func(tx *sql.Tx) error {
+				resp, err = s.withTx(tx).getPermits(ctx, ns, req.GetName(), permits, req.GetMax(), ttl)
+				return err
+			})
+			if err != nil {
+				if isRetryable(err) {
+					break
+				}
+				return nil, err
+			}
+			return resp, nil
+		}
+	}
+}
+
+// getPermits takes the permits if enough of them are left. Permits of expired holders are freed.
+// The max of a semaphore is stored while it has holders, requests with a different max are rejected.
+func (s *semaphoresServer) getPermits(ctx context.Context, ns, name string, permits, max uint32, ttl time.Duration) (resp *api.SemaphoreAcquireResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "getPermits")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+
+	var (
+		now   = time.Now()
+		token = uuid.NewV4().String()
+		where = squirrel.Eq{
+			"namespace": ns,
+			"name":      name,
+		}
+	)
+	_, err = s.getBuilder(s.db).Delete(s.table("semaphore_holders")).
+		Where(where).
+		Where(squirrel.LtOrEq{"expires_at": now}).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var taken uint32
+	err = s.getBuilder(s.db).Select("COALESCE(SUM(permits), 0)").
+		From(s.table("semaphore_holders")).
+		Where(where).
+		QueryRowContext(ctx).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setMax(ctx, ns, name, max, taken); err != nil {
+		return nil, err
+	}
+	if taken+permits > max {
+		return nil, errLocked
+	}
+	_, err = s.getBuilder(s.db).
+		Insert(s.table("semaphore_holders")).
+		Columns("namespace", "name", "owner_token", "permits", "ttl_ms", "updated_at", "expires_at").
+		Values(ns, name, token, permits, ttl.Milliseconds(), now, now.Add(ttl)).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := ptypes.TimestampProto(now.Add(ttl))
+	if err != nil {
+		return nil, err
+	}
+	return &api.SemaphoreAcquireResponse{
+		Name:      name,
+		Token:     token,
+		Permits:   permits,
+		Ttl:       ptypes.DurationProto(ttl),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// setMax stores the max of a semaphore. It may only change while no permits are taken.
+func (s *semaphoresServer) setMax(ctx context.Context, ns, name string, max, taken uint32) error {
+	where := squirrel.Eq{
+		"namespace": ns,
+		"name":      name,
+	}
+	var stored uint32
+	err := s.getBuilder(s.db).Select("max").
+		From(s.table("semaphores")).
+		Where(where).
+		QueryRowContext(ctx).Scan(&stored)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = s.getBuilder(s.db).
+			Insert(s.table("semaphores")).
+			Columns("namespace", "name", "max").
+			Values(ns, name, max).
+			ExecContext(ctx)
+		return err
+	case err != nil:
+		return err
+	case stored == max:
+		return nil
+	case taken > 0:
+		return status.Errorf(codes.FailedPrecondition, "semaphore %s is in use with max %d, not %d", name, stored, max)
+	}
+	_, err = s.getBuilder(s.db).Update(s.table("semaphores")).
+		Set("max", max).
+		Where(where).
+		ExecContext(ctx)
+	return err
+}
+
+func (s *semaphoresServer) Hold(ctx context.Context, req *api.SemaphoreHoldRequest) (resp *api.SemaphoreHoldResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "Hold")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("name", req.GetName())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "semaphores:hold", req.GetName()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token must be specified")
+	}
+
+	var (
+		now       = time.Now()
+		expiresAt time.Time
+	)
+	err = s.getBuilder(s.db).Update(s.table("semaphore_holders")).
+		Set("updated_at", now).
+		Set("expires_at", squirrel.Expr("?::timestamptz + ttl_ms * INTERVAL '1 millisecond'", now)).
+		Where(squirrel.Eq{
+			"namespace":   ns,
+			"name":        req.GetName(),
+			"owner_token": req.GetToken(),
+		}).
+		Where(squirrel.Gt{"expires_at": now}).
+		Suffix("RETURNING expires_at").
+		QueryRowContext(ctx).Scan(&expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errNotPermitHolder(req.GetName())
+		}
+		return nil, err
+	}
+	expiresAtProto, err := ptypes.TimestampProto(expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &api.SemaphoreHoldResponse{Name: req.GetName(), ExpiresAt: expiresAtProto}, nil
+}
+
+func (s *semaphoresServer) Release(ctx context.Context, req *api.SemaphoreReleaseRequest) (resp *api.SemaphoreReleaseResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "Release")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("name", req.GetName())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "semaphores:release", req.GetName()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token must be specified")
+	}
+
+	res, err := s.getBuilder(s.db).Delete(s.table("semaphore_holders")).
+		Where(squirrel.Eq{
+			"namespace":   ns,
+			"name":        req.GetName(),
+			"owner_token": req.GetToken(),
+		}).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, errNotPermitHolder(req.GetName())
+	}
+	err = channels.Notify(ctx, s.db, channels.Semaphore(s.schema, ns, req.GetName()))
+	if err != nil {
+		return nil, err
+	}
+	return &api.SemaphoreReleaseResponse{Name: req.GetName()}, nil
+}
+
+func errNotPermitHolder(name string) error {
+	return status.Errorf(codes.FailedPrecondition, "no permits of semaphore %s are held by this token", name)
+}
+
+func (s *semaphoresServer) withTx(tx *sql.Tx) *semaphoresServer {
+	return &semaphoresServer{s.Tracer, tx, s.connectString, s.schema}
+}
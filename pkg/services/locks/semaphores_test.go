@@ -0,0 +1,106 @@
+package locks
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/internal/testdb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newSemaphoresTestServer(t *testing.T) (*semaphoresServer, func()) {
+	lockSrv, cleanup := newTestServer(t)
+	srv, err := NewSemaphoresServer(context.Background(), lockSrv.db.(*sql.DB), testdb.ConnectString(), lockSrv.schema)
+	require.NoError(t, err)
+	return srv.(*semaphoresServer), cleanup
+}
+
+func TestSemaphorePermits(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Minute)()
+	srv, cleanup := newSemaphoresTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Permits: 3, Max: 2})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	first, err := srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Max: 3})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), first.GetPermits())
+	second, err := srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Permits: 2, Max: 3})
+	require.NoError(t, err)
+
+	// all permits are taken
+	_, err = srv.Acquire(ctx, &api.SemaphoreAcquireRequest{
+		Name:        "exports",
+		Max:         3,
+		WaitTimeout: ptypes.DurationProto(500 * time.Millisecond),
+	})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	_, err = srv.Hold(ctx, &api.SemaphoreHoldRequest{Name: "exports", Token: second.GetToken()})
+	require.NoError(t, err)
+	_, err = srv.Release(ctx, &api.SemaphoreReleaseRequest{Name: "exports", Token: first.GetToken()})
+	require.NoError(t, err)
+	_, err = srv.Release(ctx, &api.SemaphoreReleaseRequest{Name: "exports", Token: first.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Max: 3})
+	require.NoError(t, err)
+}
+
+func TestSemaphoreMaxMismatch(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Minute)()
+	srv, cleanup := newSemaphoresTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	held, err := srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Permits: 3, Max: 3})
+	require.NoError(t, err)
+
+	// a larger max must not bypass the limit of the current holders
+	_, err = srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Max: 100})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Max: 2})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// once all permits are released the max can be changed
+	_, err = srv.Release(ctx, &api.SemaphoreReleaseRequest{Name: "exports", Token: held.GetToken()})
+	require.NoError(t, err)
+	_, err = srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Permits: 5, Max: 5})
+	require.NoError(t, err)
+	_, err = srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Max: 3})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestSemaphoreExpiry(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Second)()
+	srv, cleanup := newSemaphoresTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	stale, err := srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "exports", Max: 1})
+	require.NoError(t, err)
+
+	// the holder never renews, so its permit is freed after the ttl
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	_, err = srv.Acquire(waitCtx, &api.SemaphoreAcquireRequest{Name: "exports", Max: 1})
+	require.NoError(t, err)
+
+	_, err = srv.Hold(ctx, &api.SemaphoreHoldRequest{Name: "exports", Token: stale.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// an expired permit can't be renewed, even if no acquirer has cleaned it up yet
+	expired, err := srv.Acquire(ctx, &api.SemaphoreAcquireRequest{Name: "reports", Max: 1})
+	require.NoError(t, err)
+	time.Sleep(1500 * time.Millisecond)
+	_, err = srv.Hold(ctx, &api.SemaphoreHoldRequest{Name: "reports", Token: expired.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
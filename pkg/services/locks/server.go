@@ -3,44 +3,67 @@ package locks
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/contiamo/go-base/pkg/tracing"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
 	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/auth"
+	"github.com/trusch/backbone-tools/pkg/channels"
+	"github.com/trusch/backbone-tools/pkg/errmap"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"github.com/trusch/backbone-tools/pkg/sqlizers"
 	"github.com/trusch/backbone-tools/pkg/ticker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 var (
 	pollInterval = 10 * time.Second
-	holdDeadline = 20 * time.Second
+	// defaultTTL is used if an acquisition doesn't ask for a ttl
+	defaultTTL = 20 * time.Second
 )
 
-func NewServer(ctx context.Context, db *sql.DB, connectString string) (api.LocksServer, error) {
+const (
+	// minTTL is the smallest ttl a client may ask for
+	minTTL = time.Second
+	// maxTTL is the largest ttl a client may ask for, locks of crashed holders must expire eventually
+	maxTTL = time.Hour
+)
+
+const (
+	// maxTryAttempts limits the retries of TryAquire on transaction conflicts
+	maxTryAttempts = 3
+	// maxWake limits the number of shared waiters woken at once
+	maxWake = 100
+)
+
+func NewServer(ctx context.Context, db *sql.DB, connectString, schema string) (api.LocksServer, error) {
 	srv := &locksServer{
 		Tracer:        tracing.NewTracer("locks", "LocksServer"),
 		db:            db,
 		connectString: connectString,
+		schema:        schema,
 	}
-	return srv, srv.init(ctx)
+	return srv, nil
 }
 
 type locksServer struct {
 	tracing.Tracer
 	db            squirrel.StdSqlCtx
 	connectString string
+	schema        string
 }
 
-func (s *locksServer) init(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `
-CREATE TABLE IF NOT EXISTS locks(
-  lock_id TEXT PRIMARY KEY,
-  updated_at TIMESTAMPTZ
-);
-`)
-	return err
+func (s *locksServer) table(name string) string {
+	return sqlizers.Table(s.schema, name)
 }
 
 func (s *locksServer) getBuilder(db squirrel.StdSqlCtx) squirrel.StatementBuilderType {
@@ -55,56 +78,359 @@ func (s *locksServer) Aquire(ctx context.Context, req *api.AquireRequest) (resp
 		s.FinishSpan(span, err)
 	}()
 	span.SetTag("lock_id", req.GetId())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "locks:aquire", req.GetId()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	ttl, err := parseTTL(req.GetTtl())
+	if err != nil {
+		return nil, err
+	}
+	span.SetTag("ttl", ttl.String())
+	span.SetTag("mode", req.GetMode().String())
+
+	waitCtx := ctx
+	if req.GetWaitTimeout() != nil {
+		timeout, err := ptypes.Duration(req.GetWaitTimeout())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		span.SetTag("wait_timeout", timeout.String())
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// waiters are queued, so the lock is granted in request order
+	w, err := s.enqueue(ctx, ns, req.GetId(), req.GetMode())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			s.dequeue(ns, req.GetId(), w)
+		}
+	}()
+	span.SetTag("waiter_id", w.id)
 
 	notifyConn, err := pgx.Connect(ctx, s.connectString)
 	if err != nil {
 		return nil, err
 	}
-	ticker := ticker.New(pollInterval, 0.1, notifyConn, "locks")
-	if err := ticker.Start(ctx); err != nil {
+	defer notifyConn.Close(context.Background())
+	ticker := ticker.New(pollInterval, 0.1, notifyConn, channels.LockWaiter(s.schema, w.id))
+	if err := ticker.Start(waitCtx); err != nil {
 		return nil, err
 	}
+	defer ticker.Stop()
 	for {
 		select {
-		case <-ctx.Done():
+		case <-waitCtx.Done():
+			if ctx.Err() == nil {
+				return nil, status.Errorf(codes.DeadlineExceeded, "timed out waiting for lock %s", req.GetId())
+			}
 			return nil, ctx.Err()
 		case <-ticker.C:
-			err := func() (err error) {
-				// setup tx
-				rawDB, ok := s.db.(*sql.DB)
-				if !ok {
-					return errors.New("can not listen withing transactions")
-				}
-				tx, err := rawDB.BeginTx(ctx, &sql.TxOptions{
-					Isolation: sql.LevelSerializable,
-				})
-				if err != nil {
-					return err
-				}
-				defer func() {
-					if err != nil {
-						tx.Rollback()
-						return
-					}
-					err = tx.Commit()
-				}()
-				err = s.withTx(tx).getLock(ctx, req.GetId())
-				if err != nil {
-					return err
-				}
-				return nil
-			}()
+			resp, err = s.tryAquire(ctx, ns, req, ttl, w)
 			if err != nil {
-				if err == errLocked {
-					break
+				if !isRetryable(err) {
+					return nil, err
+				}
+				if err := s.refreshWaiter(ctx, w); err != nil {
+					return nil, err
 				}
-				return nil, err
+				break
 			}
-			return &api.AquireResponse{
-				Id: req.GetId(),
-			}, nil
+			return resp, nil
+		}
+	}
+}
+
+func (s *locksServer) TryAquire(ctx context.Context, req *api.AquireRequest) (resp *api.TryAquireResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "TryAquire")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("lock_id", req.GetId())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "locks:aquire", req.GetId()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	ttl, err := parseTTL(req.GetTtl())
+	if err != nil {
+		return nil, err
+	}
+	span.SetTag("ttl", ttl.String())
+	span.SetTag("mode", req.GetMode().String())
+
+	for attempt := 0; attempt < maxTryAttempts; attempt++ {
+		lock, err := s.tryAquire(ctx, ns, req, ttl, nil)
+		if err == nil {
+			return &api.TryAquireResponse{Acquired: true, Lock: lock}, nil
+		}
+		if err == errLocked {
+			break
+		}
+		if !isRetryable(err) {
+			return nil, err
+		}
+	}
+	holder, err := s.getLockInfo(ctx, ns, req.GetId())
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &api.TryAquireResponse{Acquired: false, Holder: holder}, nil
+}
+
+// tryAquire makes a single attempt to take the lock in a serializable transaction.
+// Without a waiter the attempt only succeeds if nobody is queued for the lock.
+func (s *locksServer) tryAquire(ctx context.Context, ns string, req *api.AquireRequest, ttl time.Duration, w *waiter) (resp *api.AquireResponse, err error) {
+	err = serializable(ctx, s.db, func(tx *sql.Tx) error {
+		resp, err = s.withTx(tx).getLock(ctx, ns, req, ttl, w)
+		return err
+	})
+	return resp, err
+}
+
+// serializable runs fn in a serializable transaction which is committed if fn succeeds
+func serializable(ctx context.Context, db squirrel.StdSqlCtx, fn func(tx *sql.Tx) error) (err error) {
+	rawDB, ok := db.(*sql.DB)
+	if !ok {
+		return errors.New("can not listen withing transactions")
+	}
+	tx, err := rawDB.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	return fn(tx)
+}
+
+// waiter is a queued acquirer of a lock
+type waiter struct {
+	id     string
+	seq    int64
+	shared bool
+}
+
+// waiterTTL is the time a waiter stays queued without refreshing its entry
+func waiterTTL() time.Duration {
+	return 3 * pollInterval
+}
+
+// enqueue appends a waiter to the queue of a lock
+func (s *locksServer) enqueue(ctx context.Context, ns, id string, mode api.LockMode) (*waiter, error) {
+	w := &waiter{
+		id:     uuid.NewV4().String(),
+		shared: mode == api.LockMode_SHARED,
+	}
+	err := s.ensureLock(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.getBuilder(s.db).
+		Insert(s.table("lock_waiters")).
+		Columns("waiter_id", "namespace", "lock_id", "shared", "expires_at").
+		Values(w.id, ns, id, w.shared, time.Now().Add(waiterTTL())).
+		Suffix("RETURNING seq").
+		QueryRowContext(ctx).Scan(&w.seq)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// dequeue removes a waiter which gave up and wakes the waiters it blocked
+func (s *locksServer) dequeue(ns, id string, w *waiter) {
+	ctx := context.Background()
+	_, err := s.getBuilder(s.db).Delete(s.table("lock_waiters")).
+		Where(squirrel.Eq{"waiter_id": w.id}).
+		ExecContext(ctx)
+	if err != nil {
+		logrus.Error(errors.Wrap(err, "failed to dequeue lock waiter"))
+		return
+	}
+	if err := s.wakeWaiters(ctx, ns, id); err != nil {
+		logrus.Error(errors.Wrap(err, "failed to wake lock waiters"))
+	}
+}
+
+// refreshWaiter keeps a waiter queued, waiters which stop refreshing are dropped from the queue
+func (s *locksServer) refreshWaiter(ctx context.Context, w *waiter) error {
+	_, err := s.getBuilder(s.db).Update(s.table("lock_waiters")).
+		Set("expires_at", time.Now().Add(waiterTTL())).
+		Where(squirrel.Eq{"waiter_id": w.id}).
+		ExecContext(ctx)
+	return err
+}
+
+// wakeWaiters notifies the waiters at the head of the queue: the first exclusive waiter or all shared waiters up to the next exclusive one
+func (s *locksServer) wakeWaiters(ctx context.Context, ns, id string) error {
+	rows, err := s.getBuilder(s.db).Select("waiter_id", "shared").
+		From(s.table("lock_waiters")).
+		Where(squirrel.Eq{"namespace": ns, "lock_id": id}).
+		Where(squirrel.Gt{"expires_at": time.Now()}).
+		OrderBy("seq ASC").
+		Limit(maxWake).
+		QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	var next []string
+	for rows.Next() {
+		var (
+			waiterID string
+			shared   bool
+		)
+		if err := rows.Scan(&waiterID, &shared); err != nil {
+			rows.Close()
+			return err
+		}
+		if !shared {
+			if len(next) == 0 {
+				next = append(next, waiterID)
+			}
+			break
+		}
+		next = append(next, waiterID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, waiterID := range next {
+		if err := channels.Notify(ctx, s.db, channels.LockWaiter(s.schema, waiterID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureLock creates the row of a lock if it doesn't exist yet
+func (s *locksServer) ensureLock(ctx context.Context, ns, id string) error {
+	_, err := s.getBuilder(s.db).
+		Insert(s.table("locks")).
+		Columns("namespace", "lock_id", "updated_at", "fencing_token").
+		Values(ns, id, time.Now(), 0).
+		Suffix("ON CONFLICT DO NOTHING").
+		ExecContext(ctx)
+	return err
+}
+
+// parseTTL returns the ttl the client asked for or the default
+func parseTTL(d *duration.Duration) (time.Duration, error) {
+	if d == nil {
+		return defaultTTL, nil
+	}
+	ttl, err := ptypes.Duration(d)
+	if err != nil {
+		return 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if ttl < minTTL {
+		return 0, status.Errorf(codes.InvalidArgument, "ttl must be at least %s", minTTL)
+	}
+	if ttl > maxTTL {
+		return 0, status.Errorf(codes.InvalidArgument, "ttl must be at most %s", maxTTL)
+	}
+	return ttl, nil
+}
+
+// isRetryable returns true if a failed acquisition attempt should simply be retried.
+// Concurrent acquirers creating the same lock row conflict, the loser retries.
+func isRetryable(err error) bool {
+	return err == errLocked || errmap.IsSerializationFailure(err) || errmap.IsUniqueViolation(err)
+}
+
+// getLockInfo describes the current holders and waiters of a lock, it returns sql.ErrNoRows if the lock is neither held nor awaited
+func (s *locksServer) getLockInfo(ctx context.Context, ns, id string) (*api.LockInfo, error) {
+	now := time.Now()
+	rows, err := s.getBuilder(s.db).
+		Select("shared", "fencing_token", "updated_at", "expires_at", "ttl_ms", "acquired_at", "holder", "holder_address").
+		From(s.table("lock_holders")).
+		Where(squirrel.Eq{"namespace": ns, "lock_id": id}).
+		Where(squirrel.Gt{"expires_at": now}).
+		OrderBy("fencing_token DESC").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	info := &api.LockInfo{Id: id}
+	for rows.Next() {
+		var (
+			shared       bool
+			fencingToken uint64
+			updatedAt    time.Time
+			expiresAt    time.Time
+			ttlMs        int64
+			acquiredAt   time.Time
+			holder       string
+			address      string
+		)
+		if err := rows.Scan(&shared, &fencingToken, &updatedAt, &expiresAt, &ttlMs, &acquiredAt, &holder, &address); err != nil {
+			return nil, err
+		}
+		info.Holders++
+		if info.Holders > 1 {
+			continue
+		}
+		// the latest holder describes the lock
+		info.FencingToken = fencingToken
+		info.Ttl = ptypes.DurationProto(time.Duration(ttlMs) * time.Millisecond)
+		info.Mode = lockMode(shared)
+		info.Holder = holder
+		info.HolderAddress = address
+		info.UpdatedAt, err = ptypes.TimestampProto(updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		info.ExpiresAt, err = ptypes.TimestampProto(expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		info.AcquiredAt, err = ptypes.TimestampProto(acquiredAt)
+		if err != nil {
+			return nil, err
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	err = s.getBuilder(s.db).Select("COUNT(*)").
+		From(s.table("lock_waiters")).
+		Where(squirrel.Eq{"namespace": ns, "lock_id": id}).
+		Where(squirrel.Gt{"expires_at": now}).
+		QueryRowContext(ctx).Scan(&info.Waiters)
+	if err != nil {
+		return nil, err
+	}
+	if info.Holders == 0 && info.Waiters == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return info, nil
+}
+
+func lockMode(shared bool) api.LockMode {
+	if shared {
+		return api.LockMode_SHARED
+	}
+	return api.LockMode_EXCLUSIVE
 }
 
 func (s *locksServer) Hold(ctx context.Context, req *api.HoldRequest) (resp *api.HoldResponse, err error) {
@@ -113,12 +439,46 @@ func (s *locksServer) Hold(ctx context.Context, req *api.HoldRequest) (resp *api
 		s.FinishSpan(span, err)
 	}()
 	span.SetTag("lock_id", req.GetId())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "locks:hold", req.GetId()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token must be specified")
+	}
 
-	_, err = s.getBuilder(s.db).Update("locks").Set("updated_at", time.Now()).ExecContext(ctx)
+	var (
+		now          = time.Now()
+		fencingToken uint64
+		expiresAt    time.Time
+	)
+	// extend the lock by the ttl it has been acquired with, an expired lock may already be taken over
+	err = s.getBuilder(s.db).Update(s.table("lock_holders")).
+		Set("updated_at", now).
+		Set("expires_at", squirrel.Expr("?::timestamptz + ttl_ms * INTERVAL '1 millisecond'", now)).
+		Where(squirrel.Eq{
+			"namespace":   ns,
+			"lock_id":     req.GetId(),
+			"owner_token": req.GetToken(),
+		}).
+		Where(squirrel.Gt{"expires_at": now}).
+		Suffix("RETURNING fencing_token, expires_at").
+		QueryRowContext(ctx).Scan(&fencingToken, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errNotOwner(req.GetId())
+		}
+		return nil, err
+	}
+	expiresAtProto, err := ptypes.TimestampProto(expiresAt)
 	if err != nil {
 		return nil, err
 	}
-	return &api.HoldResponse{Id: req.GetId()}, nil
+	return &api.HoldResponse{Id: req.GetId(), FencingToken: fencingToken, ExpiresAt: expiresAtProto}, nil
 }
 
 func (s *locksServer) Release(ctx context.Context, req *api.ReleaseRequest) (resp *api.ReleaseResponse, err error) {
@@ -127,12 +487,133 @@ func (s *locksServer) Release(ctx context.Context, req *api.ReleaseRequest) (res
 		s.FinishSpan(span, err)
 	}()
 	span.SetTag("lock_id", req.GetId())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "locks:release", req.GetId()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token must be specified")
+	}
 
-	_, err = s.getBuilder(s.db).Update("locks").Set("updated_at", time.Time{}).ExecContext(ctx)
+	res, err := s.getBuilder(s.db).Delete(s.table("lock_holders")).
+		Where(squirrel.Eq{
+			"namespace":   ns,
+			"lock_id":     req.GetId(),
+			"owner_token": req.GetToken(),
+		}).
+		ExecContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	_, err = s.db.ExecContext(ctx, `NOTIFY locks`)
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, errNotOwner(req.GetId())
+	}
+	err = s.wakeWaiters(ctx, ns, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return &api.ReleaseResponse{Id: req.GetId()}, nil
+}
+
+func (s *locksServer) List(req *api.ListLocksRequest, resp api.Locks_ListServer) (err error) {
+	span, ctx := s.StartSpan(resp.Context(), "List")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("prefix", req.GetPrefix())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, ns, "locks:list", req.GetPrefix()+"*"); err != nil {
+		return err
+	}
+	span.SetTag("namespace", ns)
+
+	now := time.Now()
+	// only locks which are held or awaited are of interest
+	rows, err := s.getBuilder(s.db).Select("l.lock_id").
+		From(s.table("locks") + " l").
+		Where(squirrel.Eq{"l.namespace": ns}).
+		Where(squirrel.Like{"l.lock_id": sqlizers.LikePrefix(req.GetPrefix())}).
+		Where(squirrel.Or{
+			squirrel.Expr("EXISTS (SELECT 1 FROM "+s.table("lock_holders")+" h WHERE h.namespace = l.namespace AND h.lock_id = l.lock_id AND h.expires_at > ?)", now),
+			squirrel.Expr("EXISTS (SELECT 1 FROM "+s.table("lock_waiters")+" w WHERE w.namespace = l.namespace AND w.lock_id = l.lock_id AND w.expires_at > ?)", now),
+		}).
+		OrderBy("l.lock_id ASC").
+		QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		info, err := s.getLockInfo(ctx, ns, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				// released in the meantime
+				continue
+			}
+			return err
+		}
+		if err := resp.Send(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *locksServer) ForceRelease(ctx context.Context, req *api.ForceReleaseRequest) (resp *api.ReleaseResponse, err error) {
+	span, ctx := s.StartSpan(ctx, "ForceRelease")
+	defer func() {
+		s.FinishSpan(span, err)
+	}()
+	span.SetTag("lock_id", req.GetId())
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, ns, "locks:break", req.GetId()); err != nil {
+		return nil, err
+	}
+	span.SetTag("namespace", ns)
+
+	res, err := s.getBuilder(s.db).Delete(s.table("lock_holders")).
+		Where(squirrel.Eq{
+			"namespace": ns,
+			"lock_id":   req.GetId(),
+		}).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, status.Errorf(codes.NotFound, "lock %s is not held", req.GetId())
+	}
+	logrus.Warnf("force released lock %s in namespace %s (%d holders)", req.GetId(), ns, n)
+	err = s.wakeWaiters(ctx, ns, req.GetId())
 	if err != nil {
 		return nil, err
 	}
@@ -141,44 +622,141 @@ func (s *locksServer) Release(ctx context.Context, req *api.ReleaseRequest) (res
 
 var errLocked = errors.New("can't get lock")
 
-func (s *locksServer) getLock(ctx context.Context, id string) (err error) {
+func errNotOwner(id string) error {
+	return status.Errorf(codes.FailedPrecondition, "lock %s is not held by this token", id)
+}
+
+// getLock takes the lock if the requested mode is compatible with the current holders and no incompatible waiter is queued before w.
+// Expired holders and waiters are dropped. Every acquisition gets a new owner token and increments the fencing token of the lock.
+// The client provided holder name and the peer address are recorded with the holder.
+func (s *locksServer) getLock(ctx context.Context, ns string, req *api.AquireRequest, ttl time.Duration, w *waiter) (resp *api.AquireResponse, err error) {
 	span, ctx := s.StartSpan(ctx, "tryGetLock")
 	defer func() {
 		s.FinishSpan(span, err)
 	}()
+	id, mode := req.GetId(), req.GetMode()
+	span.SetTag("namespace", ns)
 	span.SetTag("lock_id", id)
+	span.SetTag("mode", mode.String())
 
 	var (
-		lockID    string
-		updatedAt time.Time
+		now    = time.Now()
+		token  = uuid.NewV4().String()
+		shared = mode == api.LockMode_SHARED
+		where  = squirrel.Eq{
+			"namespace": ns,
+			"lock_id":   id,
+		}
 	)
-	err = s.getBuilder(s.db).Select("lock_id", "updated_at").From("locks").Where(squirrel.Eq{
-		"lock_id": id,
-	}).QueryRowContext(ctx).Scan(&lockID, &updatedAt)
+	err = s.ensureLock(ctx, ns, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			_, err = s.getBuilder(s.db).
-				Insert("locks").
-				Columns("lock_id", "updated_at").
-				Values(id, time.Now()).
-				ExecContext(ctx)
-			if err != nil {
-				return err
-			}
-			return nil
-		}
-		return err
+		return nil, err
+	}
+	_, err = s.getBuilder(s.db).Delete(s.table("lock_holders")).
+		Where(where).
+		Where(squirrel.LtOrEq{"expires_at": now}).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.getBuilder(s.db).Delete(s.table("lock_waiters")).
+		Where(where).
+		Where(squirrel.LtOrEq{"expires_at": now}).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		exclusiveHolders, sharedHolders int
+		exclusiveAhead, waitersAhead    int
+	)
+	err = s.getBuilder(s.db).
+		Select(
+			"COUNT(*) FILTER (WHERE NOT shared)",
+			"COUNT(*) FILTER (WHERE shared)",
+		).
+		From(s.table("lock_holders")).
+		Where(where).
+		QueryRowContext(ctx).Scan(&exclusiveHolders, &sharedHolders)
+	if err != nil {
+		return nil, err
+	}
+	ahead := squirrel.And{where}
+	if w != nil {
+		ahead = append(ahead, squirrel.Lt{"seq": w.seq})
+	}
+	err = s.getBuilder(s.db).
+		Select(
+			"COUNT(*) FILTER (WHERE NOT shared)",
+			"COUNT(*)",
+		).
+		From(s.table("lock_waiters")).
+		Where(ahead).
+		QueryRowContext(ctx).Scan(&exclusiveAhead, &waitersAhead)
+	if err != nil {
+		return nil, err
+	}
+	if exclusiveHolders > 0 || exclusiveAhead > 0 {
+		return nil, errLocked
 	}
-	if time.Now().Sub(updatedAt) > holdDeadline {
-		_, err = s.getBuilder(s.db).Update("locks").Set("updated_at", time.Now()).ExecContext(ctx)
+	if !shared && (sharedHolders > 0 || waitersAhead > 0) {
+		return nil, errLocked
+	}
+
+	var fencingToken uint64
+	err = s.getBuilder(s.db).Update(s.table("locks")).
+		Set("updated_at", now).
+		Set("fencing_token", squirrel.Expr("fencing_token + 1")).
+		Where(where).
+		Suffix("RETURNING fencing_token").
+		QueryRowContext(ctx).Scan(&fencingToken)
+	if err != nil {
+		return nil, err
+	}
+	if w != nil {
+		_, err = s.getBuilder(s.db).Delete(s.table("lock_waiters")).
+			Where(squirrel.Eq{"waiter_id": w.id}).
+			ExecContext(ctx)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		return nil
 	}
-	return errLocked
+	_, err = s.getBuilder(s.db).
+		Insert(s.table("lock_holders")).
+		Columns("namespace", "lock_id", "owner_token", "shared", "fencing_token", "ttl_ms", "updated_at", "expires_at", "acquired_at", "holder", "holder_address").
+		Values(ns, id, token, shared, fencingToken, ttl.Milliseconds(), now, now.Add(ttl), now, req.GetHolder(), peerAddress(ctx)).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newAquireResponse(id, token, fencingToken, mode, ttl, now.Add(ttl))
+}
+
+// peerAddress returns the address of the calling client if known
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func newAquireResponse(id, token string, fencingToken uint64, mode api.LockMode, ttl time.Duration, expiresAt time.Time) (*api.AquireResponse, error) {
+	expiresAtProto, err := ptypes.TimestampProto(expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &api.AquireResponse{
+		Id:           id,
+		Token:        token,
+		FencingToken: fencingToken,
+		Ttl:          ptypes.DurationProto(ttl),
+		ExpiresAt:    expiresAtProto,
+		Mode:         mode,
+	}, nil
 }
 
 func (s *locksServer) withTx(tx *sql.Tx) *locksServer {
-	return &locksServer{s.Tracer, tx, s.connectString}
+	return &locksServer{s.Tracer, tx, s.connectString, s.schema}
 }
@@ -0,0 +1,539 @@
+package locks
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+	"github.com/trusch/backbone-tools/pkg/api"
+	"github.com/trusch/backbone-tools/pkg/internal/fakes"
+	"github.com/trusch/backbone-tools/pkg/internal/testdb"
+	"github.com/trusch/backbone-tools/pkg/namespace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// newTestServer creates a locks server working in a fresh schema which is dropped by the returned cleanup function
+func newTestServer(t *testing.T) (*locksServer, func()) {
+	db, schema, cleanup := testdb.New(t, "locks")
+	srv, err := NewServer(context.Background(), db, testdb.ConnectString(), schema)
+	require.NoError(t, err)
+	return srv.(*locksServer), cleanup
+}
+
+// setTimings changes the lock timings and returns a function restoring the defaults
+func setTimings(poll, ttl time.Duration) func() {
+	oldPoll, oldTTL := pollInterval, defaultTTL
+	pollInterval, defaultTTL = poll, ttl
+	return func() {
+		pollInterval, defaultTTL = oldPoll, oldTTL
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	ttl, err := parseTTL(nil)
+	require.NoError(t, err)
+	require.Equal(t, defaultTTL, ttl)
+	ttl, err = parseTTL(ptypes.DurationProto(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, ttl)
+	for _, d := range []time.Duration{0, 500 * time.Millisecond, maxTTL + time.Second, 365 * 24 * time.Hour} {
+		_, err = parseTTL(ptypes.DurationProto(d))
+		require.Equal(t, codes.InvalidArgument, status.Code(err), "ttl %s", d)
+	}
+}
+
+func TestMutualExclusion(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	first, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.GetToken())
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = srv.Aquire(waitCtx, &api.AquireRequest{Id: "l1"})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// other locks are not affected
+	_, err = srv.Aquire(ctx, &api.AquireRequest{Id: "l2"})
+	require.NoError(t, err)
+}
+
+func TestHoldAndReleaseAreScoped(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	l1, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	l2, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l2"})
+	require.NoError(t, err)
+
+	// the token of one lock can't be used for another one
+	_, err = srv.Hold(ctx, &api.HoldRequest{Id: "l2", Token: l1.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l2", Token: l1.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// releasing l1 leaves l2 locked
+	_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: l1.GetToken()})
+	require.NoError(t, err)
+	_, err = srv.Hold(ctx, &api.HoldRequest{Id: "l2", Token: l2.GetToken()})
+	require.NoError(t, err)
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = srv.Aquire(waitCtx, &api.AquireRequest{Id: "l2"})
+	require.Equal(t, context.DeadlineExceeded, err)
+	_, err = srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+}
+
+func TestExpiryTakeover(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Second)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	first, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+
+	// the first owner never holds the lock, so it is taken over after the deadline
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	second, err := srv.Aquire(waitCtx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.True(t, time.Since(start) >= 900*time.Millisecond)
+	require.True(t, second.GetFencingToken() > first.GetFencingToken())
+
+	// the stale owner lost the lock
+	_, err = srv.Hold(ctx, &api.HoldRequest{Id: "l1", Token: first.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = srv.Hold(ctx, &api.HoldRequest{Id: "l1", Token: second.GetToken()})
+	require.NoError(t, err)
+}
+
+func TestHoldAfterExpiry(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Second)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	lock, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+
+	// nobody took the lock over yet, but the owner must not revive it after the deadline
+	time.Sleep(1500 * time.Millisecond)
+	_, err = srv.Hold(ctx, &api.HoldRequest{Id: "l1", Token: lock.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestHoldPreventsExpiry(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Second)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	lock, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+
+	holdCtx, stopHolding := context.WithCancel(ctx)
+	defer stopHolding()
+	go func() {
+		for {
+			select {
+			case <-holdCtx.Done():
+				return
+			case <-time.After(200 * time.Millisecond):
+				_, _ = srv.Hold(holdCtx, &api.HoldRequest{Id: "l1", Token: lock.GetToken()})
+			}
+		}
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	_, err = srv.Aquire(waitCtx, &api.AquireRequest{Id: "l1"})
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestReleaseWakesWaiters(t *testing.T) {
+	// polling is effectively disabled, so only the release notification can wake the waiter
+	defer setTimings(time.Minute, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	lock, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+
+	acquired := make(chan error, 1)
+	go func() {
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		_, err := srv.Aquire(waitCtx, &api.AquireRequest{Id: "l1"})
+		acquired <- err
+	}()
+
+	time.Sleep(500 * time.Millisecond)
+	_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: lock.GetToken()})
+	require.NoError(t, err)
+
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter was not woken up by the release")
+	}
+}
+
+func TestConcurrentAcquirers(t *testing.T) {
+	defer setTimings(50*time.Millisecond, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		holders  int
+		maxSeen  int
+		fencings = make(chan uint64, 10)
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+			if !assertNoError(t, err) {
+				return
+			}
+			mu.Lock()
+			holders++
+			if holders > maxSeen {
+				maxSeen = holders
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			holders--
+			mu.Unlock()
+			fencings <- lock.GetFencingToken()
+			_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: lock.GetToken()})
+			assertNoError(t, err)
+		}()
+	}
+	wg.Wait()
+	close(fencings)
+
+	require.Equal(t, 1, maxSeen)
+	seen := make(map[uint64]bool)
+	for f := range fencings {
+		require.False(t, seen[f], "fencing token %d was handed out twice", f)
+		seen[f] = true
+	}
+	require.Len(t, seen, 10)
+}
+
+func TestTryAquire(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	first, err := srv.TryAquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.True(t, first.GetAcquired())
+	require.NotEmpty(t, first.GetLock().GetToken())
+
+	second, err := srv.TryAquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.False(t, second.GetAcquired())
+	require.Nil(t, second.GetLock())
+	require.Equal(t, "l1", second.GetHolder().GetId())
+	require.Equal(t, first.GetLock().GetFencingToken(), second.GetHolder().GetFencingToken())
+}
+
+func TestAquireWaitTimeout(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+
+	_, err = srv.Aquire(ctx, &api.AquireRequest{Id: "l1", WaitTimeout: ptypes.DurationProto(500 * time.Millisecond)})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestRequestTTL(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1", Ttl: ptypes.DurationProto(time.Millisecond)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	first, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1", Ttl: ptypes.DurationProto(time.Second)})
+	require.NoError(t, err)
+	ttl, err := ptypes.Duration(first.GetTtl())
+	require.NoError(t, err)
+	require.Equal(t, time.Second, ttl)
+
+	// holding extends by the requested ttl, not the default
+	held, err := srv.Hold(ctx, &api.HoldRequest{Id: "l1", Token: first.GetToken()})
+	require.NoError(t, err)
+	expiresAt, err := ptypes.Timestamp(held.GetExpiresAt())
+	require.NoError(t, err)
+	require.True(t, time.Until(expiresAt) <= time.Second)
+
+	// the lock expires after the short ttl although the default is a minute
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	second, err := srv.Aquire(waitCtx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.True(t, second.GetFencingToken() > first.GetFencingToken())
+}
+
+func TestSharedLocks(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	r1, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1", Mode: api.LockMode_SHARED})
+	require.NoError(t, err)
+	r2, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1", Mode: api.LockMode_SHARED})
+	require.NoError(t, err)
+	require.NotEqual(t, r1.GetToken(), r2.GetToken())
+
+	try, err := srv.TryAquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.False(t, try.GetAcquired())
+	require.Equal(t, api.LockMode_SHARED, try.GetHolder().GetMode())
+	require.Equal(t, uint32(2), try.GetHolder().GetHolders())
+
+	// the writer gets the lock once all readers are gone
+	_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: r1.GetToken()})
+	require.NoError(t, err)
+	try, err = srv.TryAquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.False(t, try.GetAcquired())
+	_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: r2.GetToken()})
+	require.NoError(t, err)
+	try, err = srv.TryAquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.True(t, try.GetAcquired())
+
+	// readers are excluded by the writer
+	try, err = srv.TryAquire(ctx, &api.AquireRequest{Id: "l1", Mode: api.LockMode_SHARED})
+	require.NoError(t, err)
+	require.False(t, try.GetAcquired())
+	require.Equal(t, api.LockMode_EXCLUSIVE, try.GetHolder().GetMode())
+}
+
+func TestWriterPreference(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	reader, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1", Mode: api.LockMode_SHARED})
+	require.NoError(t, err)
+
+	acquired := make(chan error, 1)
+	go func() {
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		_, err := srv.Aquire(waitCtx, &api.AquireRequest{Id: "l1"})
+		acquired <- err
+	}()
+	time.Sleep(500 * time.Millisecond)
+
+	// new readers queue up behind the waiting writer
+	_, err = srv.Aquire(ctx, &api.AquireRequest{
+		Id:          "l1",
+		Mode:        api.LockMode_SHARED,
+		WaitTimeout: ptypes.DurationProto(500 * time.Millisecond),
+	})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: reader.GetToken()})
+	require.NoError(t, err)
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("writer did not get the lock")
+	}
+}
+
+func TestFIFOOrder(t *testing.T) {
+	// polling is slow, so the order only depends on the queue and the wake ups on release
+	defer setTimings(time.Minute, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	lock, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		order []int
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lock, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+			if !assertNoError(t, err) {
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: lock.GetToken()})
+			assertNoError(t, err)
+		}(i)
+		// give the waiter time to queue up
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: lock.GetToken()})
+	require.NoError(t, err)
+	wg.Wait()
+	require.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
+
+func TestTryAquireDoesNotBarge(t *testing.T) {
+	defer setTimings(time.Minute, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	lock, err := srv.Aquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		_, _ = srv.Aquire(waitCtx, &api.AquireRequest{Id: "l1"})
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	// once the lock is released it belongs to the queued waiter
+	_, err = srv.Release(ctx, &api.ReleaseRequest{Id: "l1", Token: lock.GetToken()})
+	require.NoError(t, err)
+	try, err := srv.TryAquire(ctx, &api.AquireRequest{Id: "l1"})
+	require.NoError(t, err)
+	require.False(t, try.GetAcquired())
+}
+
+func TestListAndForceRelease(t *testing.T) {
+	defer setTimings(time.Minute, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4711},
+	})
+
+	_, err := srv.Aquire(ctx, &api.AquireRequest{Id: "exports/a", Holder: "worker-1"})
+	require.NoError(t, err)
+	_, err = srv.Aquire(ctx, &api.AquireRequest{Id: "imports/a"})
+	require.NoError(t, err)
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := srv.Aquire(waitCtx, &api.AquireRequest{Id: "exports/a"})
+		acquired <- err
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	stream := &fakes.ListLocksStream{Ctx: ctx}
+	require.NoError(t, srv.List(&api.ListLocksRequest{Prefix: "exports/"}, stream))
+	require.Len(t, stream.Locks, 1)
+	info := stream.Locks[0]
+	require.Equal(t, "exports/a", info.GetId())
+	require.Equal(t, "worker-1", info.GetHolder())
+	require.Equal(t, "10.0.0.1:4711", info.GetHolderAddress())
+	require.Equal(t, uint32(1), info.GetHolders())
+	require.Equal(t, uint32(1), info.GetWaiters())
+	require.NotNil(t, info.GetAcquiredAt())
+	require.NotNil(t, info.GetExpiresAt())
+
+	// breaking the lock hands it to the waiter
+	_, err = srv.ForceRelease(ctx, &api.ForceReleaseRequest{Id: "exports/a"})
+	require.NoError(t, err)
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter was not woken up by the forced release")
+	}
+	_, err = srv.ForceRelease(ctx, &api.ForceReleaseRequest{Id: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	stream = &fakes.ListLocksStream{Ctx: ctx}
+	require.NoError(t, srv.List(&api.ListLocksRequest{}, stream))
+	require.Len(t, stream.Locks, 2)
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	defer setTimings(100*time.Millisecond, time.Minute)()
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	ctxA := namespace.NewContext(context.Background(), "team-a")
+	ctxB := namespace.NewContext(context.Background(), "team-b")
+
+	a, err := srv.Aquire(ctxA, &api.AquireRequest{Id: "l1", Holder: "worker-a"})
+	require.NoError(t, err)
+
+	// a lock with the same id in another namespace is independent
+	b, err := srv.TryAquire(ctxB, &api.AquireRequest{Id: "l1", Holder: "worker-b"})
+	require.NoError(t, err)
+	require.True(t, b.GetAcquired())
+	_, err = srv.Hold(ctxB, &api.HoldRequest{Id: "l1", Token: a.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = srv.Release(ctxB, &api.ReleaseRequest{Id: "l1", Token: a.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	stream := &fakes.ListLocksStream{Ctx: ctxB}
+	require.NoError(t, srv.List(&api.ListLocksRequest{}, stream))
+	require.Len(t, stream.Locks, 1)
+	require.Equal(t, "worker-b", stream.Locks[0].GetHolder())
+	_, err = srv.ForceRelease(ctxB, &api.ForceReleaseRequest{Id: "l1"})
+	require.NoError(t, err)
+	_, err = srv.Hold(ctxA, &api.HoldRequest{Id: "l1", Token: a.GetToken()})
+	require.NoError(t, err)
+
+	// so are semaphores
+	semaphores, err := NewSemaphoresServer(context.Background(), srv.db.(*sql.DB), testdb.ConnectString(), srv.schema)
+	require.NoError(t, err)
+	permit, err := semaphores.Acquire(ctxA, &api.SemaphoreAcquireRequest{Name: "exports", Max: 1})
+	require.NoError(t, err)
+	_, err = semaphores.Acquire(ctxB, &api.SemaphoreAcquireRequest{Name: "exports", Max: 2, WaitTimeout: ptypes.DurationProto(time.Second)})
+	require.NoError(t, err)
+	_, err = semaphores.Release(ctxB, &api.SemaphoreReleaseRequest{Name: "exports", Token: permit.GetToken()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func assertNoError(t *testing.T, err error) bool {
+	if err != nil {
+		t.Error(err)
+		return false
+	}
+	return true
+}
@@ -3,8 +3,24 @@ package sqlizers
 import (
 	"sort"
 	"strings"
+
+	"github.com/jackc/pgx/v4"
 )
 
+// Table returns the quoted and schema qualified name of a table.
+// If schema is empty the unqualified name is returned so the connections search_path applies.
+func Table(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+	return pgx.Identifier{schema, name}.Sanitize()
+}
+
+// LikePrefix returns a LIKE pattern matching all strings starting with prefix
+func LikePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+}
+
 type JSONContains map[string]interface{}
 
 func (s JSONContains) ToSql() (sql string, args []interface{}, err error) {
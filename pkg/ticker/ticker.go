@@ -3,6 +3,7 @@ package ticker
 import (
 	"context"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v4"
@@ -19,6 +20,8 @@ type Ticker struct {
 	stop           chan struct{}
 	db             *pgx.Conn
 	dbEventChannel string
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
 }
 
 // New creates a new ticker sleeping randomly for (interval +/- jitter*interval)
@@ -32,53 +35,65 @@ func New(interval time.Duration, jitterFactor float64, db *pgx.Conn, dbEventChan
 	return t
 }
 
-// Start starts the ticker
+// Start starts the ticker, it runs until ctx is done or Stop is called
 func (t *Ticker) Start(ctx context.Context) error {
 	t.C = make(chan struct{})
 	dbNotifyChannel := make(chan struct{})
 	if t.db != nil {
-		_, err := t.db.Exec(ctx, "LISTEN "+t.dbEventChannel)
+		_, err := t.db.Exec(ctx, "LISTEN "+pgx.Identifier{t.dbEventChannel}.Sanitize())
 		if err != nil {
 			return err
 		}
+	}
+	ctx, t.cancel = context.WithCancel(ctx)
+	if t.db != nil {
+		t.wg.Add(1)
 		go func() {
+			defer t.wg.Done()
 			defer func() { logrus.Debug("returning from db notification listener") }()
 			for {
+				if _, err := t.db.WaitForNotification(ctx); err != nil {
+					if ctx.Err() == nil {
+						logrus.Error(errors.Wrap(err, "failed to wait for notifications"))
+					}
+					return
+				}
 				select {
 				case <-ctx.Done():
 					return
-				default:
-					if _, err := t.db.WaitForNotification(ctx); err == nil {
-						dbNotifyChannel <- struct{}{}
-					} else {
-						logrus.Error(errors.Wrap(err, "failed to wait for notifications"))
-						return
-					}
+				case dbNotifyChannel <- struct{}{}:
 				}
 			}
 		}()
 	}
 
+	t.wg.Add(1)
 	go func() {
-		t.C <- struct{}{} // initial tick comes immediatly
+		defer t.wg.Done()
 		defer func() {
 			logrus.Debug("returning from ticker main loop listener")
-			close(dbNotifyChannel)
 			close(t.C)
 		}()
+		tick := true // initial tick comes immediatly
 		for {
+			var c chan struct{}
+			if tick {
+				c = t.C
+			}
 			duration := t.jitter(t.interval)
 			timer := time.NewTimer(duration)
 			select {
 			case <-ctx.Done():
 				timer.Stop()
 				return
+			case c <- struct{}{}:
+				tick = false
 			case <-timer.C:
 				logrus.Debug("tick because of timer")
-				t.C <- struct{}{}
+				tick = true
 			case <-dbNotifyChannel:
 				logrus.Debug("tick because of database notification")
-				t.C <- struct{}{}
+				tick = true
 			}
 			timer.Stop()
 		}
@@ -86,3 +101,13 @@ func (t *Ticker) Start(ctx context.Context) error {
 
 	return nil
 }
+
+// Stop stops the ticker and waits until it doesn't use its database connection anymore,
+// so the connection can be closed afterwards
+func (t *Ticker) Stop() {
+	if t.cancel == nil {
+		return
+	}
+	t.cancel()
+	t.wg.Wait()
+}
@@ -30,3 +30,16 @@ func TestTicker(t *testing.T) {
 		last = now
 	}
 }
+
+func TestStop(t *testing.T) {
+	ticker := New(10*time.Millisecond, 0.1, nil, "")
+	require.NoError(t, ticker.Start(context.Background()))
+	<-ticker.C
+
+	// pending ticks nobody reads don't block stopping
+	time.Sleep(50 * time.Millisecond)
+	ticker.Stop()
+	_, ok := <-ticker.C
+	require.False(t, ok)
+	ticker.Stop()
+}